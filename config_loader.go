@@ -1,9 +1,12 @@
 package configloader
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"maps"
+	"sync"
 
 	"github.com/snippetaccumulator/configloader/fieldsetter"
 )
@@ -12,7 +15,9 @@ import (
 // main configuration and override configuration from specified paths and filenames, applying deserializers for
 // each configuration format, and dynamically overriding specific configuration fields via a map of paths to values.
 // Fields include Name, Path, OverrideName, OverridePath for file locations, Deserializer, and OverrideDeserializer
-// for handling specific data formats, and Overrides for field-specific overrides.
+// for handling specific data formats, Sources for additional configuration layers, and FieldSetter to control
+// how the paths of field-specific overrides are resolved. Override, Overrides and Load are safe for concurrent
+// use, while the fields must not be modified once the ConfigLoader is in use.
 type ConfigLoader struct {
 	Name                 string
 	Path                 string
@@ -20,12 +25,25 @@ type ConfigLoader struct {
 	OverridePath         string
 	Deserializer         DeserializerFunc
 	OverrideDeserializer DeserializerFunc
-	Overrides            map[string]any
+	Sources              []Source
+	FieldSetter          fieldsetter.Setter
+	Strict               bool
+	Optional             bool
+	OverrideOptional     bool
+	SearchPaths          []string
+	SearchMerge          bool
+	FS                   fs.FS
+	OverrideFS           fs.FS
+	MainSource           Source
+	OverrideSource       Source
+	mu                   sync.RWMutex
+	overrides            map[string]any
+	provenance           *provenanceRecorder
 }
 
 // NewConfigLoader creates and returns a new instance of ConfigLoader with the specified name. It initializes
 // the ConfigLoader's fields with default values: current directory for Path, empty for OverrideName and
-// OverridePath, nil for Deserializer and OverrideDeserializer, and an empty map for Overrides. Additional
+// OverridePath, nil for Deserializer and OverrideDeserializer, and no overrides. Additional
 // configurations can be applied using Option functions passed as arguments to this function, allowing for
 // customization of the loader's behavior and settings.
 func NewConfigLoader(name string, options ...Option) *ConfigLoader {
@@ -35,7 +53,7 @@ func NewConfigLoader(name string, options ...Option) *ConfigLoader {
 		OverrideName: "",
 		OverridePath: "",
 		Deserializer: nil,
-		Overrides:    make(map[string]any),
+		overrides:    make(map[string]any),
 	}
 	for _, option := range options {
 		option(loader)
@@ -43,54 +61,140 @@ func NewConfigLoader(name string, options ...Option) *ConfigLoader {
 	return loader
 }
 
-// Load reads the main configuration file based on the ConfigLoader's Path and Name, deserializes it into
-// the provided config object using the set Deserializer, and applies any Overrides. If OverridePath and
-// OverrideName are set, it also loads and applies an override configuration file using either the OverrideDeserializer
-// or the main Deserializer if no OverrideDeserializer is set. Errors during file reading, deserialization, or
-// field setting are returned. This method facilitates the flexible loading and merging of configurations with
-// optional overrides to tailor application settings dynamically.
+// Load applies all configuration layers onto the provided config object, in order of increasing precedence:
+//
+//  1. the defaults given by `default` struct tags (see ApplyDefaults), where the defaults of slices and maps
+//     are only applied after all sources and only if none of them set the field,
+//  2. the MainSource if set, or else the main configuration file based on Path and Name, or the first match
+//     for Name in SearchPaths (all matches if SearchMerge is set, see SearchSource), using the Deserializer,
+//  3. the OverrideSource if set, or else the override configuration file based on OverridePath and
+//     OverrideName, if both are set, using either the OverrideDeserializer or the main Deserializer if no
+//     OverrideDeserializer is set,
+//  4. every entry of Sources, in the order they were added,
+//  5. the programmatic overrides set with Override.
+//
+// A Name or OverrideName of "-" (StdinName) reads the respective configuration from the standard input. The
+// main and override files are read from FS and OverrideFS respectively, or from the OS filesystem if those
+// are not set. If no deserializer is set for a file, the deserializer registered for the file's extension is
+// used (see RegisterDeserializer). Files are required unless they are marked optional with WithOptionalFile
+// or WithOptionalOverrideFile; missing optional files are skipped and listed by SkippedSources, while a
+// missing required file fails with a *SourceNotFoundError. Every layer can overwrite the values set by the
+// layers before it. Once all layers are applied, the result is checked with Validate. Which layer set each
+// field is recorded and can be inspected with Explain and Provenance. Errors during file reading,
+// deserialization, field setting, or validation are returned. This method facilitates the flexible loading
+// and merging of configurations with optional overrides to tailor application settings dynamically.
 func (c *ConfigLoader) Load(config any) error {
-	if c.Deserializer == nil {
-		return fmt.Errorf("no deserializer set for main configuration")
-	}
+	return c.LoadContext(context.Background(), config)
+}
 
-	configData, err := os.ReadFile(filepath.Join(c.Path, c.Name))
-	if err != nil {
-		return err
+// LoadContext works like Load, but aborts loading with the context's error once the given context is done.
+// The context is checked between the layers and passed on to every source implementing ContextSource and
+// every deserializer implementing ContextDeserializer.
+func (c *ConfigLoader) LoadContext(ctx context.Context, config any) error {
+	if c.Deserializer == nil && c.MainSource == nil {
+		if _, ok := DeserializerForFile(c.Name); !ok {
+			return fmt.Errorf("no deserializer set for main configuration")
+		}
 	}
 
-	err = c.Deserializer.Deserialize(configData, config)
-	if err != nil {
+	recorder := newProvenanceRecorder(config)
+	if err := applyDefaultsTo(config, scalarDefaults); err != nil {
 		return err
 	}
+	recorder.record(config, "defaults", nil)
 
-	if c.OverrideName != "" && c.OverridePath != "" {
-		if c.OverrideDeserializer == nil {
-			c.OverrideDeserializer = c.Deserializer
-		}
-		overrideData, err := os.ReadFile(filepath.Join(c.OverridePath, c.OverrideName))
-		if err != nil {
+	for _, source := range expandLayers(c.layers()) {
+		positions, err := applySource(ctx, source, config)
+		if skipped(source, err) {
+			recorder.skip(source.String())
+			continue
+		} else if err != nil {
 			return err
 		}
+		recorder.record(config, source.String(), positions)
+	}
 
-		err = c.OverrideDeserializer.Deserialize(overrideData, config)
-		if err != nil {
-			return err
-		}
+	if err := applyDefaultsTo(config, containerDefaults); err != nil {
+		return err
+	}
+	recorder.record(config, "defaults", nil)
+
+	overrides := c.Overrides()
+	if err := errors.Join(c.FieldSetter.SetFields(config, overrides, true)...); err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		paths = append(paths, path)
 	}
+	recorder.record(config, "override", appliedPaths(paths...))
+
+	c.mu.Lock()
+	c.provenance = recorder
+	c.mu.Unlock()
 
-	errs := fieldsetter.SetFields(config, c.Overrides, true)
-	if len(errs) > 0 {
-		return fmt.Errorf("error setting fields: %+v", errs)
+	return Validate(config)
+}
+
+// layers returns the file based sources of the ConfigLoader in the order they are applied.
+func (c *ConfigLoader) layers() []Source {
+	var layers []Source
+	switch {
+	case c.MainSource != nil:
+		layers = append(layers, c.MainSource)
+	case c.Name == StdinName:
+		main := NewStdinSource(c.Deserializer)
+		main.Strict = c.Strict
+		layers = append(layers, main)
+	case len(c.SearchPaths) > 0:
+		main := NewSearchSource(c.Name, c.SearchPaths, c.Deserializer)
+		main.FS, main.Merge, main.Strict, main.Optional = c.FS, c.SearchMerge, c.Strict, c.Optional
+		layers = append(layers, main)
+	default:
+		main := NewFileSource(c.Path, c.Name, c.Deserializer)
+		main.FS, main.Strict, main.Optional = c.FS, c.Strict, c.Optional
+		layers = append(layers, main)
 	}
 
-	return nil
+	overrideDeserializer := c.OverrideDeserializer
+	if overrideDeserializer == nil {
+		overrideDeserializer = c.Deserializer
+	}
+	switch {
+	case c.OverrideSource != nil:
+		layers = append(layers, c.OverrideSource)
+	case c.OverrideName == StdinName:
+		override := NewStdinSource(overrideDeserializer)
+		override.Strict = c.Strict
+		layers = append(layers, override)
+	case c.OverrideName != "" && c.OverridePath != "":
+		override := NewFileSource(c.OverridePath, c.OverrideName, overrideDeserializer)
+		override.FS, override.Strict, override.Optional = c.OverrideFS, c.Strict, c.OverrideOptional
+		layers = append(layers, override)
+	}
+	return append(layers, c.Sources...)
 }
 
 // Override adds or updates a specific configuration override by path. The path should specify the target field
 // within the configuration object, and the value is what will be set for this field when applying overrides.
+// Overrides are applied parents before children, so overriding both "Nested" and "Nested.Field3" always
+// results in the value of "Nested" with Field3 replaced, regardless of the order Override was called in.
 // This method allows for dynamic adjustments to the configuration, even after the initial loading process.
 func (c *ConfigLoader) Override(path string, value any) error {
-	c.Overrides[path] = value
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.overrides == nil {
+		c.overrides = make(map[string]any)
+	}
+	c.overrides[path] = value
 	return nil
 }
+
+// Overrides returns a snapshot of the overrides set on the ConfigLoader. Changes to the returned map do not
+// affect the ConfigLoader. It replaces the former exported Overrides field: reads of the field become reads
+// of the returned map, and writes to the field become calls to Override.
+func (c *ConfigLoader) Overrides() map[string]any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.overrides)
+}
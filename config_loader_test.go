@@ -1,10 +1,15 @@
 package configloader_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/snippetaccumulator/configloader"
 )
@@ -57,11 +62,11 @@ func TestLoadWithYAMLDeserializer(t *testing.T) {
 
 func TestDeserializerNotSet(t *testing.T) {
 	var config Config
-	loader := configloader.NewConfigLoader("nonexistent.yaml", configloader.WithPath("."))
+	loader := configloader.NewConfigLoader("config.unregistered", configloader.WithPath("."))
 
 	err := loader.Load(&config)
-	if err == nil {
-		t.Error("Expected an error when deserializer is not set, got nil")
+	if err == nil || !strings.Contains(err.Error(), "no deserializer set") {
+		t.Errorf("Expected an error when deserializer is not set, got %v", err)
 	}
 }
 
@@ -178,6 +183,21 @@ func TestLoadWithEmbeddedStructIndirectly(t *testing.T) {
 	}
 }
 
+func TestOverrideStructLiteral(t *testing.T) {
+	loader := &configloader.ConfigLoader{MainSource: configloader.NewBytesSource("config.yaml", []byte("field1: value1"), nil)}
+	if err := loader.Override("Field2", 2); err != nil {
+		t.Fatalf("Failed to set override: %s", err)
+	}
+
+	var config Config
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.Field1 != "value1" || config.Field2 != 2 {
+		t.Errorf("Configuration is not correct; got: %+v", config)
+	}
+}
+
 func TestOverrideWithEmbeddedStruct(t *testing.T) {
 	configData := []byte("config:\n  field1: value1\n  field2: 2\n  nested:\n    field3: true\nfield4: 3.14")
 	filename, err := createTempYAMLFile(configData)
@@ -211,3 +231,326 @@ func TestOverrideWithEmbeddedStruct(t *testing.T) {
 		t.Errorf("Expected 'field4' to be 3.14, got %f", config.Field4)
 	}
 }
+
+func TestLoadWithSources(t *testing.T) {
+	mainFilename, err := createTempYAMLFile([]byte("field1: main1\nfield2: 1\nnested:\n  field3: false"))
+	if err != nil {
+		t.Fatalf("Unable to create main temp YAML file: %s", err)
+	}
+	defer os.Remove(mainFilename)
+
+	envFilename, err := createTempYAMLFile([]byte("field1: env1\nfield2: 2"))
+	if err != nil {
+		t.Fatalf("Unable to create environment temp YAML file: %s", err)
+	}
+	defer os.Remove(envFilename)
+
+	localFilename, err := createTempYAMLFile([]byte("field2: 3"))
+	if err != nil {
+		t.Fatalf("Unable to create local temp YAML file: %s", err)
+	}
+	defer os.Remove(localFilename)
+
+	var config Config
+	loader := configloader.NewConfigLoader(filepath.Base(mainFilename),
+		configloader.WithPath(filepath.Dir(mainFilename)),
+		configloader.WithDeserializer(new(configloader.YAMLDeserializer)),
+		configloader.WithSource(
+			configloader.NewFileSource(filepath.Dir(envFilename), filepath.Base(envFilename), new(configloader.YAMLDeserializer)),
+			configloader.NewFileSource(filepath.Dir(localFilename), filepath.Base(localFilename), new(configloader.YAMLDeserializer)),
+		),
+	)
+	loader.Override("Nested.Field3", true)
+
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration with sources: %s", err)
+	}
+
+	if config.Field1 != "env1" {
+		t.Errorf("Expected field1 to be 'env1', got '%s'", config.Field1)
+	}
+	if config.Field2 != 3 {
+		t.Errorf("Expected field2 to be 3, got %d", config.Field2)
+	}
+	if config.Nested.Field3 != true {
+		t.Errorf("Expected nested.field3 to be true, got %t", config.Nested.Field3)
+	}
+}
+
+func TestLoadDetectsDeserializerFromExtension(t *testing.T) {
+	filename, err := createTempYAMLFile([]byte("field1: value1\nfield2: 2\nnested:\n  field3: true"))
+	if err != nil {
+		t.Fatalf("Unable to create temp YAML file: %s", err)
+	}
+	defer os.Remove(filename)
+
+	var config Config
+	loader := configloader.NewConfigLoader(filepath.Base(filename), configloader.WithPath(filepath.Dir(filename)))
+
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if config.Field1 != "value1" {
+		t.Errorf("Expected field1 to be 'value1', got '%s'", config.Field1)
+	}
+	if config.Field2 != 2 {
+		t.Errorf("Expected field2 to be 2, got %d", config.Field2)
+	}
+}
+
+type upperDeserializer struct{}
+
+func (u *upperDeserializer) Deserialize(data []byte, v any) error {
+	v.(*Config).Field1 = strings.ToUpper(strings.TrimSpace(string(data)))
+	return nil
+}
+
+func TestRegisterDeserializer(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.upper"), []byte("value1\n"), 0o600); err != nil {
+		t.Fatalf("Unable to write config file: %s", err)
+	}
+
+	var config Config
+	loader := configloader.NewConfigLoader("config.upper", configloader.WithPath(dir))
+	if err := loader.Load(&config); err == nil {
+		t.Fatal("Expected an error for an unregistered extension, got nil")
+	}
+
+	configloader.RegisterDeserializer("upper", new(upperDeserializer))
+	t.Cleanup(func() { configloader.UnregisterDeserializer("upper") })
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if config.Field1 != "VALUE1" {
+		t.Errorf("Expected field1 to be 'VALUE1', got '%s'", config.Field1)
+	}
+}
+
+type Server struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+type ServerConfig struct {
+	Server Server `yaml:"server"`
+}
+
+func TestOverridesAreDeterministic(t *testing.T) {
+	filename, err := createTempYAMLFile([]byte("server:\n  host: localhost\n  port: 80"))
+	if err != nil {
+		t.Fatalf("Unable to create temp YAML file: %s", err)
+	}
+	defer os.Remove(filename)
+
+	loader := configloader.NewConfigLoader(filepath.Base(filename), configloader.WithPath(filepath.Dir(filename)))
+	loader.Override("Server.Port", 8080)
+	loader.Override("Server", Server{Host: "example.com", Port: 443})
+	loader.Override("Server.Host", "api.example.com")
+
+	for i := 0; i < 50; i++ {
+		var config ServerConfig
+		if err := loader.Load(&config); err != nil {
+			t.Fatalf("Failed to load configuration: %s", err)
+		}
+		if config.Server.Host != "api.example.com" || config.Server.Port != 8080 {
+			t.Fatalf("Overrides were not applied parent before child; got %+v", config.Server)
+		}
+	}
+}
+
+type ValidatedConfig struct {
+	Field1 string `yaml:"field1" validate:"required"`
+	Field2 int    `yaml:"field2" validate:"min=1,max=10"`
+}
+
+func TestLoadValidates(t *testing.T) {
+	filename, err := createTempYAMLFile([]byte("field2: 20"))
+	if err != nil {
+		t.Fatalf("Unable to create temp YAML file: %s", err)
+	}
+	defer os.Remove(filename)
+
+	var config ValidatedConfig
+	loader := configloader.NewConfigLoader(filepath.Base(filename), configloader.WithPath(filepath.Dir(filename)))
+
+	err = loader.Load(&config)
+	var validationErr *configloader.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a validation error, got: %v", err)
+	}
+	if len(validationErr.Errors) != 2 {
+		t.Errorf("Expected 2 validation errors, got: %s", err)
+	}
+
+	loader.Override("Field1", "value1")
+	loader.Override("Field2", 5)
+	if err := loader.Load(&config); err != nil {
+		t.Errorf("Expected the overridden configuration to be valid, got: %s", err)
+	}
+}
+
+func TestConcurrentOverrideAndLoad(t *testing.T) {
+	filename, err := createTempYAMLFile([]byte("field1: value1\nfield2: 2"))
+	if err != nil {
+		t.Fatalf("Unable to create temp YAML file: %s", err)
+	}
+	defer os.Remove(filename)
+
+	loader := configloader.NewConfigLoader(filepath.Base(filename), configloader.WithPath(filepath.Dir(filename)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				loader.Override("Field2", i*100+j)
+				loader.Override(fmt.Sprintf("Field%d", i%2+1), fmt.Sprint(j))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				var config Config
+				if err := loader.Load(&config); err != nil {
+					t.Errorf("Failed to load configuration: %s", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	overrides := loader.Overrides()
+	if _, ok := overrides["Field2"]; !ok {
+		t.Errorf("Expected Field2 in overrides, got %v", overrides)
+	}
+	overrides["Field1"] = "modified"
+	if loader.Overrides()["Field1"] == "modified" {
+		t.Error("Modifying the snapshot should not affect the loader")
+	}
+}
+
+type blockingSource struct{}
+
+func (b *blockingSource) Apply(config any) error {
+	return errors.New("blockingSource must be applied with a context")
+}
+
+func (b *blockingSource) ApplyContext(ctx context.Context, config any) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *blockingSource) String() string {
+	return "blocking source"
+}
+
+func TestLoadContextTimeout(t *testing.T) {
+	filename, err := createTempYAMLFile([]byte("field1: value1"))
+	if err != nil {
+		t.Fatalf("Unable to create temp YAML file: %s", err)
+	}
+	defer os.Remove(filename)
+
+	loader := configloader.NewConfigLoader(filepath.Base(filename),
+		configloader.WithPath(filepath.Dir(filename)),
+		configloader.WithSource(new(blockingSource)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var config Config
+	if err := loader.LoadContext(ctx, &config); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline to be exceeded, got: %v", err)
+	}
+}
+
+func TestLoadContextCanceled(t *testing.T) {
+	filename, err := createTempYAMLFile([]byte("field1: value1"))
+	if err != nil {
+		t.Fatalf("Unable to create temp YAML file: %s", err)
+	}
+	defer os.Remove(filename)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var config Config
+	loader := configloader.NewConfigLoader(filepath.Base(filename), configloader.WithPath(filepath.Dir(filename)))
+	if err := loader.LoadContext(ctx, &config); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the load to be canceled, got: %v", err)
+	}
+	if config.Field1 != "" {
+		t.Errorf("Expected no configuration to be loaded, got: %+v", config)
+	}
+
+	mock := configloader.NewMockLoader(map[string]any{"Field1": "value1"})
+	if err := mock.LoadContext(ctx, &config); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the mock load to be canceled, got: %v", err)
+	}
+}
+
+func TestOptionalFiles(t *testing.T) {
+	mainFilename, err := createTempYAMLFile([]byte("field1: main1\nfield2: 1"))
+	if err != nil {
+		t.Fatalf("Unable to create main temp YAML file: %s", err)
+	}
+	defer os.Remove(mainFilename)
+	dir := t.TempDir()
+
+	var config Config
+	loader := configloader.NewConfigLoader(filepath.Base(mainFilename),
+		configloader.WithPath(filepath.Dir(mainFilename)),
+		configloader.WithOptionalOverrideFile(dir, "local.yaml"),
+		configloader.WithSource(&configloader.FileSource{Path: dir, Name: "extra.yaml", Optional: true}),
+	)
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration with missing optional files: %s", err)
+	}
+	if config.Field1 != "main1" {
+		t.Errorf("Expected field1 to be 'main1', got '%s'", config.Field1)
+	}
+
+	skipped := loader.SkippedSources()
+	expected := []string{filepath.Join(dir, "local.yaml"), filepath.Join(dir, "extra.yaml")}
+	if fmt.Sprint(skipped) != fmt.Sprint(expected) {
+		t.Errorf("Expected skipped sources %v, got %v", expected, skipped)
+	}
+	if report := loader.ProvenanceReport(); !strings.Contains(report, filepath.Join(dir, "local.yaml")+" skipped") {
+		t.Errorf("Expected the report to list the skipped override file, got:\n%s", report)
+	}
+
+	config = Config{}
+	loader = configloader.NewConfigLoader("config.yaml", configloader.WithPath(dir), configloader.WithOptionalFile())
+	loader.Override("Field2", 2)
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration with missing optional main file: %s", err)
+	}
+	if config.Field2 != 2 {
+		t.Errorf("Expected field2 to be 2, got %d", config.Field2)
+	}
+}
+
+func TestRequiredFileNotFound(t *testing.T) {
+	dir := t.TempDir()
+	var config Config
+	loader := configloader.NewConfigLoader("config.yaml", configloader.WithPath(dir),
+		configloader.WithOverrideFile(dir, "local.yaml"))
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("field1: main1"), 0o644); err != nil {
+		t.Fatalf("Unable to write main file: %s", err)
+	}
+
+	err := loader.Load(&config)
+	var notFound *configloader.SourceNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected a SourceNotFoundError, got %v", err)
+	}
+	if len(notFound.Tried) != 1 || notFound.Tried[0] != filepath.Join(dir, "local.yaml") {
+		t.Errorf("Expected the override file to be listed as tried, got %v", notFound.Tried)
+	}
+}
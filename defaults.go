@@ -0,0 +1,160 @@
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/snippetaccumulator/configloader/fieldsetter"
+)
+
+// DefaultTag is the struct tag holding the default value of a configuration field.
+const DefaultTag = "default"
+
+// ApplyDefaults sets every zero-valued field of the given config object that has a `default:"..."` tag to the
+// value of the tag. The values are converted into the type of the field with the same rules that apply to
+// overrides, so slices and arrays are given as comma separated lists (`default:"a,b,c"`) and maps as
+// key=value pairs (`default:"a=1,b=2"`). Nested and embedded structs, non-nil pointers to structs and the
+// struct elements of slices, arrays and maps are descended into. Nil pointers are left nil, so that optional
+// sections modelled as pointers stay unset unless a source sets them. Fields that already hold a value are
+// left untouched. Errors for all fields whose default could not be applied are returned together.
+//
+// When loading, only the defaults of fields outside of pointers, slices and maps are applied before the
+// sources. The defaults of slices and maps are applied once all configuration files, environment variables
+// and other sources are applied, and only if none of them set the field, so that a file listing some of the
+// keys of a map replaces the default map instead of being merged into it. The defaults within structs that
+// the sources added, like the elements of slices and maps or sections behind pointers, are applied at that
+// point as well, to all of their fields that are still zero.
+func ApplyDefaults(config any) error {
+	return applyDefaultsTo(config, allDefaults)
+}
+
+// Defaults returns a configuration of type T with all defaults applied and everything else left at its
+// zero value. This is useful to document or print the default configuration of an application.
+func Defaults[T any]() (T, error) {
+	var config T
+	err := ApplyDefaults(&config)
+	return config, err
+}
+
+// defaultsPhase selects which defaults are applied, so that loaders can apply the defaults of slices and maps,
+// and those within the structs the sources added, after the sources.
+type defaultsPhase int
+
+const (
+	allDefaults defaultsPhase = iota
+	scalarDefaults
+	containerDefaults
+)
+
+// applies reports whether defaults for fields of the given type are applied in the phase.
+func (p defaultsPhase) applies(t reflect.Type) bool {
+	container := t.Kind() == reflect.Slice || t.Kind() == reflect.Map
+	switch p {
+	case scalarDefaults:
+		return !container
+	case containerDefaults:
+		return container
+	default:
+		return true
+	}
+}
+
+func applyDefaultsTo(config any, phase defaultsPhase) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("config must be a non-nil pointer")
+	}
+	return errors.Join(applyDefaults(v.Elem(), "", phase)...)
+}
+
+func applyDefaults(v reflect.Value, prefix string, phase defaultsPhase) []error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if phase == containerDefaults {
+			// Sections behind pointers are usually allocated by a source, so all of their defaults apply.
+			phase = allDefaults
+		}
+		return applyDefaults(v.Elem(), prefix, phase)
+	case reflect.Slice, reflect.Array:
+		if phase == scalarDefaults || !hasDefaults(v.Type().Elem(), map[reflect.Type]bool{}) {
+			return nil
+		}
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, applyDefaults(v.Index(i), prefix+strconv.Itoa(i)+".", allDefaults)...)
+		}
+		return errs
+	case reflect.Map:
+		if phase == scalarDefaults || !hasDefaults(v.Type().Elem(), map[reflect.Type]bool{}) {
+			return nil
+		}
+		// Map values are not addressable, so the defaults are applied to a copy that replaces the value.
+		var errs []error
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			key := fmt.Sprint(iter.Key().Interface())
+			errs = append(errs, applyDefaults(elem, prefix+key+".", allDefaults)...)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+		return errs
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		path := prefix + field.Name
+		fieldValue := v.Field(i)
+		if tag, ok := field.Tag.Lookup(DefaultTag); ok && fieldValue.IsZero() && phase.applies(field.Type) {
+			value, err := fieldsetter.Convert(tag, field.Type)
+			if err != nil {
+				fieldErr := &FieldError{Path: path, Err: err}
+				var convertErr *FieldError
+				if errors.As(err, &convertErr) {
+					fieldErr.Expected, fieldErr.Actual, fieldErr.Err = convertErr.Expected, convertErr.Actual, convertErr.Err
+				}
+				errs = append(errs, fieldErr)
+				continue
+			}
+			fieldValue.Set(value)
+		}
+		errs = append(errs, applyDefaults(fieldValue, path+".", phase)...)
+	}
+	return errs
+}
+
+// hasDefaults reports whether the given type is a struct, or a pointer, slice, array or map of them, with a
+// default for any of its fields or the fields of its nested structs. Types in seen are being checked already, which ends the
+// recursion for self-referencing types.
+func hasDefaults(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if _, ok := field.Tag.Lookup(DefaultTag); ok || hasDefaults(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
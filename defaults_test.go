@@ -0,0 +1,178 @@
+package configloader
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type DefaultsConfig struct {
+	Host    string         `default:"localhost"`
+	Port    int            `default:"8080"`
+	Timeout time.Duration  `default:"30s"`
+	Tags    []string       `default:"a, b,c"`
+	Limits  map[string]int `default:"read=10,write=5"`
+	Plain   string
+	Nested  struct {
+		Enabled bool    `default:"true"`
+		Ratio   float64 `default:"0.5"`
+	}
+	Pointer *struct {
+		Name string `default:"pointer"`
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	config, err := Defaults[DefaultsConfig]()
+	if err != nil {
+		t.Fatalf("Failed to apply defaults: %s", err)
+	}
+
+	if config.Host != "localhost" || config.Port != 8080 || config.Timeout != 30*time.Second {
+		t.Errorf("Scalar defaults are not set correctly; got: %+v", config)
+	}
+	if !reflect.DeepEqual(config.Tags, []string{"a", "b", "c"}) {
+		t.Errorf("Tags are not set correctly; got: %v", config.Tags)
+	}
+	if !reflect.DeepEqual(config.Limits, map[string]int{"read": 10, "write": 5}) {
+		t.Errorf("Limits are not set correctly; got: %v", config.Limits)
+	}
+	if config.Plain != "" {
+		t.Errorf("Plain should not be set; got: %s", config.Plain)
+	}
+	if !config.Nested.Enabled || config.Nested.Ratio != 0.5 {
+		t.Errorf("Nested defaults are not set correctly; got: %+v", config.Nested)
+	}
+	if config.Pointer != nil {
+		t.Errorf("Nil pointers should not be allocated for defaults; got: %+v", config.Pointer)
+	}
+}
+
+func TestApplyDefaultsKeepsValues(t *testing.T) {
+	config := DefaultsConfig{Host: "example.com"}
+	config.Pointer = &struct {
+		Name string `default:"pointer"`
+	}{}
+
+	if err := ApplyDefaults(&config); err != nil {
+		t.Fatalf("Failed to apply defaults: %s", err)
+	}
+
+	if config.Host != "example.com" {
+		t.Errorf("Host should not be overwritten; got: %s", config.Host)
+	}
+	if config.Pointer.Name != "pointer" {
+		t.Errorf("Defaults of non-nil pointers should be applied; got: %s", config.Pointer.Name)
+	}
+}
+
+func TestApplyDefaultsElements(t *testing.T) {
+	type Server struct {
+		Host string
+		Port int `default:"80"`
+	}
+	config := struct {
+		Servers  []Server
+		Named    map[string]Server
+		Pointers map[string]*Server
+	}{
+		Servers:  []Server{{Host: "a"}, {Host: "b", Port: 8080}},
+		Named:    map[string]Server{"api": {Host: "api"}},
+		Pointers: map[string]*Server{"db": {Host: "db"}},
+	}
+	if err := ApplyDefaults(&config); err != nil {
+		t.Fatalf("Failed to apply defaults: %s", err)
+	}
+	if config.Servers[0].Port != 80 || config.Servers[1].Port != 8080 {
+		t.Errorf("Expected the defaults of slice elements to be applied; got: %+v", config.Servers)
+	}
+	if config.Named["api"].Port != 80 || config.Pointers["db"].Port != 80 {
+		t.Errorf("Expected the defaults of map values to be applied; got: %+v, %+v", config.Named, config.Pointers["db"])
+	}
+}
+
+type TLSDefaults struct {
+	Cert   string `yaml:"cert"`
+	MinTLS string `yaml:"min_tls" default:"1.2"`
+}
+
+type SectionsConfig struct {
+	TLS     *TLSDefaults `yaml:"tls"`
+	Servers []struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port" default:"80"`
+	} `yaml:"servers"`
+}
+
+func TestLoadDefaultsSections(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "servers:\n  - host: a\n  - host: b\n    port: 8080\n")
+
+	var config SectionsConfig
+	loader := NewConfigLoader("config.yaml", WithPath(dir))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.TLS != nil {
+		t.Errorf("Expected a section no source set to stay nil; got: %+v", config.TLS)
+	}
+	if len(config.Servers) != 2 || config.Servers[0].Port != 80 || config.Servers[1].Port != 8080 {
+		t.Errorf("Expected the defaults of the elements from the file; got: %+v", config.Servers)
+	}
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "tls:\n  cert: cert.pem\n")
+	config = SectionsConfig{}
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.TLS == nil || config.TLS.Cert != "cert.pem" || config.TLS.MinTLS != "1.2" {
+		t.Errorf("Expected the defaults of a section set by the file; got: %+v", config.TLS)
+	}
+}
+
+func TestLoadDefaultsNotMerged(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "limits:\n  read: 1\n")
+
+	var config DefaultsConfig
+	loader := NewConfigLoader("config.yaml", WithPath(dir))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if !reflect.DeepEqual(config.Limits, map[string]int{"read": 1}) {
+		t.Errorf("Expected the map from the file to replace the default; got: %v", config.Limits)
+	}
+	if !reflect.DeepEqual(config.Tags, []string{"a", "b", "c"}) {
+		t.Errorf("Expected the default of a slice no source set; got: %v", config.Tags)
+	}
+	if provenance, _ := loader.Explain("Tags.0"); provenance.Source != "defaults" {
+		t.Errorf("Expected Tags.0 to come from the defaults; got: %s", provenance)
+	}
+}
+
+func TestApplyDefaultsInvalid(t *testing.T) {
+	var config struct {
+		Port  int  `default:"eighty"`
+		Debug bool `default:"sometimes"`
+	}
+	if err := ApplyDefaults(&config); err == nil {
+		t.Fatal("Expected an error for invalid defaults, got nil")
+	}
+}
+
+func TestMockLoader_Defaults(t *testing.T) {
+	loader := NewMockLoader(map[string]any{"Port": 9090})
+
+	var config DefaultsConfig
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if config.Host != "localhost" {
+		t.Errorf("Host default is not set correctly; got: %s", config.Host)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Port should be set by the mock data; got: %d", config.Port)
+	}
+}
@@ -1,10 +1,16 @@
 package configloader
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"reflect"
 
 	"github.com/BurntSushi/toml"
 	env "github.com/Netflix/go-env"
+	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +22,25 @@ func (jd *JSONDeserializer) Deserialize(data []byte, v any) error {
 	return json.Unmarshal(data, v)
 }
 
+// DeserializeStrict works like Deserialize, but fails with an UnknownKeyError for every object key that does
+// not map to a field of v.
+func (jd *JSONDeserializer) DeserializeStrict(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+	var generic any
+	if json.Unmarshal(data, &generic) != nil {
+		return err
+	}
+	if unknown := jsonKeys.unknownKeyErrors(generic, v, locateKeys(jd, data)); unknown != nil {
+		return unknown
+	}
+	return err
+}
+
 // YAMLDeserializer is a struct with no fields, implementing the DeserializerFunc interface for YAML data.
 // It offers a method to deserialize YAML encoded data into a Go value.
 type YAMLDeserializer struct{}
@@ -24,6 +49,25 @@ func (yd *YAMLDeserializer) Deserialize(data []byte, v any) error {
 	return yaml.Unmarshal(data, v)
 }
 
+// DeserializeStrict works like Deserialize, but fails with an UnknownKeyError for every mapping key that
+// does not map to a field of v.
+func (yd *YAMLDeserializer) DeserializeStrict(data []byte, v any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	var generic any
+	if yaml.Unmarshal(data, &generic) != nil {
+		return err
+	}
+	if unknown := yamlKeys.unknownKeyErrors(generic, v, locateKeys(yd, data)); unknown != nil {
+		return unknown
+	}
+	return err
+}
+
 // TOMLDeserializer implements the DeserializerFunc interface for TOML data.
 // It provides a method to deserialize TOML encoded data into a Go value.
 type TOMLDeserializer struct{}
@@ -32,10 +76,65 @@ func (td *TOMLDeserializer) Deserialize(data []byte, v any) error {
 	return toml.Unmarshal(data, v)
 }
 
+// DeserializeStrict works like Deserialize, but fails with an UnknownKeyError for every key that does not
+// map to a field of v. Keys inside an unknown table are not reported separately.
+func (td *TOMLDeserializer) DeserializeStrict(data []byte, v any) error {
+	metadata, err := toml.Decode(string(data), v)
+	if err != nil {
+		return err
+	}
+	undecoded := make(map[string]bool)
+	for _, key := range metadata.Undecoded() {
+		undecoded[key.String()] = true
+	}
+	var errs []error
+	for _, key := range metadata.Undecoded() {
+		if len(key) > 1 && undecoded[key[:len(key)-1].String()] {
+			continue
+		}
+		errs = append(errs, tomlKeys.unknownKeyError(reflect.TypeOf(v), key, nil))
+	}
+	return errors.Join(errs...)
+}
+
+// ProcessEnvMode controls how the EnvDeserializer combines the contents of a .env file with the environment
+// of the running process.
+type ProcessEnvMode int
+
+const (
+	// ProcessEnvIgnore only uses the variables defined in the .env file.
+	ProcessEnvIgnore ProcessEnvMode = iota
+	// ProcessEnvFallback uses the process environment for variables that are not defined in the .env file.
+	ProcessEnvFallback
+	// ProcessEnvOverlay lets the process environment take precedence over the variables of the .env file.
+	ProcessEnvOverlay
+)
+
 // EnvDeserializer implements the DeserializerFunc interface for environment variable data like .env files.
-type EnvDeserializer struct{}
+// The data is parsed as a .env file, supporting quoted and multi-line values, `export` prefixes and ${VAR}
+// expansion, and the resulting variables are mapped onto the fields tagged with `env`. ProcessEnv controls
+// whether and how the environment of the running process is taken into account as well.
+type EnvDeserializer struct {
+	ProcessEnv ProcessEnvMode
+}
 
-func (ed *EnvDeserializer) Deserialize(_ []byte, v any) error {
-	_, err := env.UnmarshalFromEnviron(v)
-	return err
+func (ed *EnvDeserializer) Deserialize(data []byte, v any) error {
+	vars, err := godotenv.UnmarshalBytes(data)
+	if err != nil {
+		return err
+	}
+
+	if ed.ProcessEnv != ProcessEnvIgnore {
+		processVars, err := env.EnvironToEnvSet(os.Environ())
+		if err != nil {
+			return err
+		}
+		for key, value := range processVars {
+			if _, ok := vars[key]; !ok || ed.ProcessEnv == ProcessEnvOverlay {
+				vars[key] = value
+			}
+		}
+	}
+
+	return env.Unmarshal(vars, v)
 }
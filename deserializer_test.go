@@ -0,0 +1,66 @@
+package configloader
+
+import (
+	"testing"
+)
+
+type EnvConfig struct {
+	Host    string `env:"HOST"`
+	Port    int    `env:"PORT"`
+	URL     string `env:"URL"`
+	Message string `env:"MESSAGE"`
+}
+
+func TestEnvDeserializer_Deserialize(t *testing.T) {
+	data := []byte("export HOST=localhost\nPORT=8080\nURL=\"http://${HOST}:${PORT}\"\nMESSAGE=\"first\\nsecond\"\n")
+
+	var config EnvConfig
+	if err := new(EnvDeserializer).Deserialize(data, &config); err != nil {
+		t.Fatalf("Failed to deserialize env data: %s", err)
+	}
+
+	if config.Host != "localhost" {
+		t.Errorf("Host is not set correctly; expected: %s, got: %s", "localhost", config.Host)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Port is not set correctly; expected: %d, got: %d", 8080, config.Port)
+	}
+	if config.URL != "http://localhost:8080" {
+		t.Errorf("URL is not set correctly; expected: %s, got: %s", "http://localhost:8080", config.URL)
+	}
+	if config.Message != "first\nsecond" {
+		t.Errorf("Message is not set correctly; expected: %q, got: %q", "first\nsecond", config.Message)
+	}
+}
+
+func TestEnvDeserializer_ProcessEnv(t *testing.T) {
+	t.Setenv("HOST", "process-host")
+	t.Setenv("MESSAGE", "process-message")
+	data := []byte("HOST=file-host\nPORT=8080\n")
+
+	tests := []struct {
+		name        string
+		mode        ProcessEnvMode
+		wantHost    string
+		wantMessage string
+	}{
+		{name: "Ignore", mode: ProcessEnvIgnore, wantHost: "file-host", wantMessage: ""},
+		{name: "Fallback", mode: ProcessEnvFallback, wantHost: "file-host", wantMessage: "process-message"},
+		{name: "Overlay", mode: ProcessEnvOverlay, wantHost: "process-host", wantMessage: "process-message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config EnvConfig
+			deserializer := &EnvDeserializer{ProcessEnv: tt.mode}
+			if err := deserializer.Deserialize(data, &config); err != nil {
+				t.Fatalf("Failed to deserialize env data: %s", err)
+			}
+			if config.Host != tt.wantHost {
+				t.Errorf("Host is not set correctly; expected: %s, got: %s", tt.wantHost, config.Host)
+			}
+			if config.Message != tt.wantMessage {
+				t.Errorf("Message is not set correctly; expected: %s, got: %s", tt.wantMessage, config.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// kubernetesDataDir is the symlink through which Kubernetes exposes the files of mounted ConfigMaps and
+// Secrets. Updates are applied by writing a new timestamped directory and atomically swapping the symlink.
+const kubernetesDataDir = "..data"
+
+// DirSource is a Source that maps a directory with one file per key onto the configuration object, the layout
+// of mounted Kubernetes ConfigMaps and Secrets, Docker secrets in /run/secrets and systemd credentials. The
+// name of each file is split at Separator, or at "." and "_" if no Separator is set, and mapped onto the
+// fields of the configuration the same way as the names of environment variables are by EnvSource, so that
+// the file database.host or DATABASE_HOST sets the field Database.Host. The contents of the file, without a
+// trailing newline, are parsed into the type of the targeted field. Hidden files, directories and files that do
+// not map onto a field are ignored.
+//
+// If the directory contains the "..data" symlink of a Kubernetes volume, all files are read through its
+// current target, so that a Load never mixes files from before and after an update. A missing directory
+// results in a *SourceNotFoundError unless Optional is set. A DirSource without a Path applies nothing.
+type DirSource struct {
+	Path      string
+	Separator string
+	Optional  bool
+}
+
+// NewDirSource creates a new DirSource for the files in the given directory.
+func NewDirSource(path string) *DirSource {
+	return &DirSource{
+		Path: path,
+	}
+}
+
+// NewCredentialsSource creates a new DirSource for the credentials systemd passes to a service in the
+// directory named by $CREDENTIALS_DIRECTORY. If the variable is not set, the source applies nothing.
+func NewCredentialsSource() *DirSource {
+	return NewDirSource(os.Getenv("CREDENTIALS_DIRECTORY"))
+}
+
+// Apply sets every field of the given config object that is addressed by a file in the directory. Files are
+// applied in lexical order of their names.
+func (d *DirSource) Apply(config any) error {
+	return d.ApplyContext(context.Background(), config)
+}
+
+// ApplyContext works like Apply, but stops once the context is done.
+func (d *DirSource) ApplyContext(ctx context.Context, config any) error {
+	var errs []error
+	for _, layer := range d.layers() {
+		if _, err := applySource(ctx, layer, config); err != nil {
+			if skipped(layer, err) {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// layers returns a layer for every file in the directory, so that the loader records the file that set each
+// field. Which fields the files map onto is resolved when they are applied.
+func (d *DirSource) layers() []Source {
+	if d.Path == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(d.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Source{&missingDirSource{dir: d, err: err}}
+	} else if err != nil {
+		return []Source{&failedSource{name: d.String(), err: err}}
+	}
+
+	root := d.Path
+	if target, err := filepath.EvalSymlinks(filepath.Join(d.Path, kubernetesDataDir)); err == nil {
+		root = target
+	}
+
+	var layers []Source
+	for _, entry := range entries {
+		name := entry.Name()
+		// Hidden entries include the ..data symlink and the timestamped directories of Kubernetes volumes.
+		if strings.HasPrefix(name, ".") || entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(root, name)
+		info, err := os.Stat(file)
+		if err != nil {
+			// The file is not part of the current ..data target, or it is a dangling symlink.
+			file = filepath.Join(d.Path, name)
+			info, err = os.Stat(file)
+		}
+		if err == nil && info.IsDir() {
+			// Symlinks to directories, like the nested paths of projected volumes, hold no single key.
+			continue
+		}
+		layers = append(layers, &keyFileSource{dir: d, name: name, file: file})
+	}
+	sort.Slice(layers, func(i, j int) bool {
+		return layers[i].(*keyFileSource).name < layers[j].(*keyFileSource).name
+	})
+	return layers
+}
+
+// segments splits the given file name into the segments that are mapped onto the configuration.
+func (d *DirSource) segments(name string) []nameSegment {
+	if d.Separator != "" {
+		return splitName(name, d.Separator)
+	}
+	return splitName(name, ".", "_")
+}
+
+// String returns the directory of the DirSource.
+func (d *DirSource) String() string {
+	return d.Path
+}
+
+// WatchPaths returns the directory of the DirSource, so that changes to any of its files, including the swap
+// of the "..data" symlink, trigger a reload.
+func (d *DirSource) WatchPaths() []string {
+	if d.Path == "" {
+		return nil
+	}
+	return []string{d.Path}
+}
+
+// keyFileSource is the layer of a DirSource for a single file.
+type keyFileSource struct {
+	dir  *DirSource
+	name string
+	file string
+}
+
+func (k *keyFileSource) Apply(config any) error {
+	_, err := k.applyLocated(context.Background(), config)
+	return err
+}
+
+func (k *keyFileSource) applyLocated(ctx context.Context, config any) (*keyPositions, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, ok := resolveEnvPath(reflect.TypeOf(config), k.dir.segments(k.name))
+	if !ok || len(path) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(k.file)
+	if err != nil {
+		return nil, err
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if err := envSetter.SetPath(config, path, value); err != nil {
+		return nil, err
+	}
+	return appliedPaths(strings.Join(path, ".")), nil
+}
+
+func (k *keyFileSource) String() string {
+	return filepath.Join(k.dir.Path, k.name)
+}
+
+// missingDirSource is the layer of a DirSource whose directory does not exist.
+type missingDirSource struct {
+	dir *DirSource
+	err error
+}
+
+func (m *missingDirSource) Apply(config any) error {
+	return &SourceNotFoundError{Source: m.dir.Path, Tried: []string{m.dir.Path}, Err: m.err}
+}
+
+func (m *missingDirSource) String() string {
+	return m.dir.String()
+}
+
+func (m *missingDirSource) optional() bool {
+	return m.dir.Optional
+}
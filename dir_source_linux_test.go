@@ -0,0 +1,113 @@
+//go:build linux
+
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// mountVersion imitates Kubernetes updating a mounted volume: the files are written into a new timestamped
+// directory, and the ..data symlink is atomically swapped to point to it.
+func mountVersion(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+	versionDir := filepath.Join(dir, "..2024_01_01_"+version)
+	if err := os.Mkdir(versionDir, 0o700); err != nil {
+		t.Fatalf("Unable to create %s: %s", versionDir, err)
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(versionDir, name), content)
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); err != nil {
+			if err := os.Symlink(filepath.Join("..data", name), link); err != nil {
+				t.Fatalf("Unable to create symlink: %s", err)
+			}
+		}
+	}
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(versionDir), tmp); err != nil {
+		t.Fatalf("Unable to create symlink: %s", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("Unable to swap symlink: %s", err)
+	}
+}
+
+func TestDirSourceKubernetesVolume(t *testing.T) {
+	dir := t.TempDir()
+	mountVersion(t, dir, "1", map[string]string{"database.password": "first"})
+
+	source := NewDirSource(dir)
+	var config DirConfig
+	if err := source.Apply(&config); err != nil {
+		t.Fatalf("Failed to apply directory: %s", err)
+	}
+	if config.Database.Password != "first" {
+		t.Errorf("Expected the password of the first version; got: %q", config.Database.Password)
+	}
+
+	mountVersion(t, dir, "2", map[string]string{"database.password": "second"})
+	if err := source.Apply(&config); err != nil {
+		t.Fatalf("Failed to apply directory: %s", err)
+	}
+	if config.Database.Password != "second" {
+		t.Errorf("Expected the password of the swapped version; got: %q", config.Database.Password)
+	}
+}
+
+func TestDirSourceWatch(t *testing.T) {
+	for _, mode := range []WatchMode{WatchNotify, WatchPoll} {
+		dir := t.TempDir()
+		mountVersion(t, dir, "1", map[string]string{"database.host": "first"})
+
+		loader := NewConfigLoader("config.yaml", WithPath(dir), WithOptionalFile(), WithSource(NewDirSource(dir)))
+		watcher, err := loader.Watch(func() any { return new(DirConfig) },
+			WithWatchMode(mode),
+			WithPollInterval(20*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatalf("Failed to watch configuration: %s", err)
+		}
+
+		updates := make(chan *DirConfig, 10)
+		watcher.Subscribe(func(config any) { updates <- config.(*DirConfig) })
+
+		mountVersion(t, dir, "2", map[string]string{"database.host": "second"})
+		select {
+		case config := <-updates:
+			if config.Database.Host != "second" {
+				t.Errorf("Reloaded configuration is not correct; got: %+v", config)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("Timed out waiting for the configuration to be reloaded in mode %d", mode)
+		}
+		watcher.Close()
+	}
+}
+
+func TestDirSourceProjectedVolume(t *testing.T) {
+	dir := t.TempDir()
+	mountVersion(t, dir, "1", map[string]string{"database.host": "db.local", "timeout": "5s"})
+	// Projected volumes expose items with nested paths through a symlink to a directory within ..data.
+	if err := os.Mkdir(filepath.Join(dir, "..data", "certs"), 0o700); err != nil {
+		t.Fatalf("Unable to create directory: %s", err)
+	}
+	writeFile(t, filepath.Join(dir, "..data", "certs", "ca.pem"), "certificate")
+	if err := os.Symlink(filepath.Join("..data", "certs"), filepath.Join(dir, "certs")); err != nil {
+		t.Fatalf("Unable to create symlink: %s", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "labels"), 0o700); err != nil {
+		t.Fatalf("Unable to create directory: %s", err)
+	}
+
+	var config DirConfig
+	loader := NewConfigLoader("config.yaml", WithPath(dir), WithOptionalFile(), WithSource(NewDirSource(dir)))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.Database.Host != "db.local" || config.Timeout != 5*time.Second {
+		t.Errorf("Configuration is not correct; got: %+v", config)
+	}
+}
@@ -0,0 +1,70 @@
+package configloader
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type DirConfig struct {
+	Database struct {
+		Host     string
+		Password string
+		MaxConns int
+	}
+	Timeout time.Duration
+	Labels  map[string]string
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "database.host"), "db.local\n")
+	writeFile(t, filepath.Join(dir, "DATABASE_MAX_CONNS"), "10")
+	writeFile(t, filepath.Join(dir, "timeout"), "5s\n")
+	writeFile(t, filepath.Join(dir, "labels.team"), "core")
+	writeFile(t, filepath.Join(dir, "labels.app.kubernetes.io"), "web")
+	writeFile(t, filepath.Join(dir, "unrelated"), "ignored")
+	writeFile(t, filepath.Join(dir, ".hidden"), "ignored")
+
+	var config DirConfig
+	loader := NewConfigLoader("config.yaml", WithPath(dir), WithOptionalFile(), WithSource(NewDirSource(dir)))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.Database.Host != "db.local" || config.Database.MaxConns != 10 || config.Timeout != 5*time.Second ||
+		config.Labels["team"] != "core" || config.Labels["app.kubernetes.io"] != "web" {
+		t.Errorf("Configuration is not correct; got: %+v", config)
+	}
+	if provenance, _ := loader.Explain("Timeout"); provenance.Source != filepath.Join(dir, "timeout") {
+		t.Errorf("Expected Timeout to come from its file; got: %s", provenance)
+	}
+}
+
+func TestDirSourceMissing(t *testing.T) {
+	var config DirConfig
+	missing := filepath.Join(t.TempDir(), "secrets")
+	var notFound *SourceNotFoundError
+	if err := NewDirSource(missing).Apply(&config); !errors.As(err, &notFound) {
+		t.Errorf("Expected a SourceNotFoundError; got: %v", err)
+	}
+	if err := (&DirSource{Path: missing, Optional: true}).Apply(&config); err != nil {
+		t.Errorf("Expected a missing optional directory to be skipped; got: %s", err)
+	}
+
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	if err := NewCredentialsSource().Apply(&config); err != nil {
+		t.Errorf("Expected no credentials to apply nothing; got: %s", err)
+	}
+}
+
+func TestDirSourceFieldError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "timeout"), "soon")
+
+	var config DirConfig
+	var fieldErr *FieldError
+	if err := NewDirSource(dir).Apply(&config); !errors.As(err, &fieldErr) || fieldErr.Path != "Timeout" {
+		t.Errorf("Expected a FieldError for Timeout; got: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snippetaccumulator/configloader/fieldsetter"
+)
+
+// envSetter is used to apply environment variables, growing slices so that MYAPP_SERVERS_3_PORT works without
+// the configuration files defining four servers.
+var envSetter = fieldsetter.Setter{GrowSlices: true}
+
+// EnvSource is a Source that applies environment variables starting with Prefix onto the configuration object.
+// The remainder of each variable name is split at Separator and mapped onto the fields of the configuration,
+// so that with the prefix "MYAPP" the variable MYAPP_DATABASE_HOST sets the field Database.Host and
+// MYAPP_SERVERS_0_PORT sets the Port of the first element of Servers. Field names are matched
+// case-insensitively and without regard to underscores, so MYAPP_DATABASE_MAX_CONNS also sets the field
+// Database.MaxConns. Map keys are taken verbatim from the variable name; for maps of scalar values the key is
+// the whole remainder of the name, so MYAPP_LABELS_team_name sets Labels["team_name"]. The string values of the
+// variables are parsed into the types of the targeted fields. Variables that do not map onto a field are
+// ignored.
+type EnvSource struct {
+	Prefix    string
+	Separator string
+}
+
+// NewEnvSource creates a new EnvSource for environment variables with the given prefix, using "_" to separate
+// the prefix and the path segments.
+func NewEnvSource(prefix string) *EnvSource {
+	return &EnvSource{
+		Prefix:    prefix,
+		Separator: "_",
+	}
+}
+
+// Apply sets every field of the given config object that is addressed by a prefixed environment variable.
+// Variables are applied in lexical order of their names.
+func (e *EnvSource) Apply(config any) error {
+	_, err := e.applyLocated(context.Background(), config)
+	return err
+}
+
+// applyLocated works like Apply, reporting the paths of the fields that were set.
+func (e *EnvSource) applyLocated(ctx context.Context, config any) (*keyPositions, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	separator := e.Separator
+	if separator == "" {
+		separator = "_"
+	}
+	prefix := e.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, separator) {
+		prefix += separator
+	}
+
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			vars[name] = value
+		}
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	configType := reflect.TypeOf(config)
+	var applied []string
+	var errs []error
+	for _, name := range names {
+		path, ok := resolveEnvPath(configType, splitName(strings.TrimPrefix(name, prefix), separator))
+		if !ok {
+			continue
+		}
+		if err := envSetter.SetPath(config, path, vars[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		applied = append(applied, strings.Join(path, "."))
+	}
+	return appliedPaths(applied...), errors.Join(errs...)
+}
+
+// String returns a description of the environment variables the EnvSource reads from.
+func (e *EnvSource) String() string {
+	return fmt.Sprintf("environment variables with prefix %q", e.Prefix)
+}
+
+// nameSegment is a segment of an environment variable or file name, together with the remainder of the name
+// starting at the segment.
+type nameSegment struct {
+	text string
+	rest string
+}
+
+// splitName splits name at every occurrence of any of the given separators.
+func splitName(name string, separators ...string) []nameSegment {
+	var segments []nameSegment
+	start := 0
+	for i := 0; i < len(name); {
+		n := 0
+		for _, separator := range separators {
+			if separator != "" && strings.HasPrefix(name[i:], separator) {
+				n = len(separator)
+				break
+			}
+		}
+		if n == 0 {
+			i++
+			continue
+		}
+		segments = append(segments, nameSegment{text: name[start:i], rest: name[start:]})
+		i += n
+		start = i
+	}
+	return append(segments, nameSegment{text: name[start:], rest: name[start:]})
+}
+
+// resolveEnvPath maps the segments of an environment variable name onto a fieldsetter path within the type t.
+// Struct fields may span multiple segments, which is tried from the shortest to the longest match. The key of
+// a map with scalar values is the whole remainder of the name, separators included, so that keys containing
+// the separator are kept intact.
+func resolveEnvPath(t reflect.Type, segments []nameSegment) ([]string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if len(segments) == 0 {
+		return nil, true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := reflect.VisibleFields(t)
+		for n := 1; n <= len(segments); n++ {
+			var name strings.Builder
+			for _, segment := range segments[:n] {
+				name.WriteString(segment.text)
+			}
+			normalized := normalizeEnvName(name.String())
+			for _, field := range fields {
+				if !field.IsExported() || normalizeEnvName(field.Name) != normalized {
+					continue
+				}
+				if rest, ok := resolveEnvPath(field.Type, segments[n:]); ok {
+					return append([]string{field.Name}, rest...), true
+				}
+			}
+		}
+		return nil, false
+	case reflect.Slice, reflect.Array:
+		if _, err := strconv.Atoi(segments[0].text); err != nil {
+			return nil, false
+		}
+		rest, ok := resolveEnvPath(t.Elem(), segments[1:])
+		return append([]string{segments[0].text}, rest...), ok
+	case reflect.Map:
+		if isLeafType(t.Elem()) {
+			return []string{segments[0].rest}, true
+		}
+		rest, ok := resolveEnvPath(t.Elem(), segments[1:])
+		return append([]string{segments[0].text}, rest...), ok
+	default:
+		return nil, false
+	}
+}
+
+func normalizeEnvName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
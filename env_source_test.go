@@ -0,0 +1,85 @@
+package configloader
+
+import (
+	"testing"
+)
+
+type EnvSourceConfig struct {
+	Database struct {
+		Host     string
+		MaxConns int
+	}
+	Servers []struct {
+		Port    uint16
+		Enabled bool
+	}
+	Labels map[string]string
+	Debug  bool
+}
+
+func TestEnvSource_Apply(t *testing.T) {
+	t.Setenv("MYAPP_DATABASE_HOST", "db.local")
+	t.Setenv("MYAPP_DATABASE_MAX_CONNS", "25")
+	t.Setenv("MYAPP_SERVERS_1_PORT", "8080")
+	t.Setenv("MYAPP_SERVERS_1_ENABLED", "true")
+	t.Setenv("MYAPP_LABELS_team", "core")
+	t.Setenv("MYAPP_LABELS_team_name", "platform")
+	t.Setenv("MYAPP_LABELS_app.kubernetes.io", "web")
+	t.Setenv("MYAPP_UNKNOWN", "ignored")
+	t.Setenv("OTHER_DEBUG", "true")
+
+	config := EnvSourceConfig{Labels: make(map[string]string)}
+	config.Servers = make([]struct {
+		Port    uint16
+		Enabled bool
+	}, 2)
+
+	if err := NewEnvSource("MYAPP").Apply(&config); err != nil {
+		t.Fatalf("Failed to apply environment: %s", err)
+	}
+
+	if config.Database.Host != "db.local" {
+		t.Errorf("Database.Host is not set correctly; expected: %s, got: %s", "db.local", config.Database.Host)
+	}
+	if config.Database.MaxConns != 25 {
+		t.Errorf("Database.MaxConns is not set correctly; expected: %d, got: %d", 25, config.Database.MaxConns)
+	}
+	if config.Servers[1].Port != 8080 || !config.Servers[1].Enabled {
+		t.Errorf("Servers.1 is not set correctly; got: %+v", config.Servers[1])
+	}
+	if config.Labels["team"] != "core" {
+		t.Errorf("Labels.team is not set correctly; expected: %s, got: %s", "core", config.Labels["team"])
+	}
+	if config.Labels["app.kubernetes.io"] != "web" {
+		t.Errorf("Labels.app.kubernetes.io is not set correctly; got: %v", config.Labels)
+	}
+	if config.Labels["team_name"] != "platform" {
+		t.Errorf("Labels.team_name is not set correctly; expected: %s, got: %s", "platform", config.Labels["team_name"])
+	}
+	if config.Debug {
+		t.Errorf("Debug should not be set by a variable without the prefix")
+	}
+}
+
+func TestEnvSource_Separator(t *testing.T) {
+	t.Setenv("MYAPP__DATABASE__MAX_CONNS", "10")
+
+	var config EnvSourceConfig
+	source := &EnvSource{Prefix: "MYAPP", Separator: "__"}
+	if err := source.Apply(&config); err != nil {
+		t.Fatalf("Failed to apply environment: %s", err)
+	}
+
+	if config.Database.MaxConns != 10 {
+		t.Errorf("Database.MaxConns is not set correctly; expected: %d, got: %d", 10, config.Database.MaxConns)
+	}
+}
+
+func TestEnvSource_InvalidValue(t *testing.T) {
+	t.Setenv("MYAPP_DATABASE_MAX_CONNS", "many")
+
+	var config EnvSourceConfig
+	if err := NewEnvSource("MYAPP").Apply(&config); err == nil {
+		t.Fatal("Expected an error for a value that cannot be parsed, got nil")
+	}
+}
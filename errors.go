@@ -0,0 +1,124 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/snippetaccumulator/configloader/fieldsetter"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError describes a value that could not be set at a field path, be it from an override, a default, an
+// environment variable or a value in a configuration file of the wrong type. Expected and Actual name the
+// type of the field and the type of the value where known.
+type FieldError = fieldsetter.FieldError
+
+// SourceNotFoundError is returned when a required configuration file does not exist. Source names the file
+// that was looked for and Tried lists every location it was looked for at. Err holds the underlying error, so
+// errors.Is(err, fs.ErrNotExist) holds as well.
+type SourceNotFoundError struct {
+	Source string
+	Tried  []string
+	Err    error
+}
+
+func (e *SourceNotFoundError) Error() string {
+	return fmt.Sprintf("configuration file %s not found, tried: %s", e.Source, strings.Join(e.Tried, ", "))
+}
+
+func (e *SourceNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when a configuration file cannot be deserialized. File names the file, and Line and
+// Column give the position of the error where the format reports it, or are zero otherwise. Err holds the
+// error of the deserializer, which is a *FieldError for values of the wrong type where the position of the
+// value is known.
+type DecodeError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	switch {
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Err)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Err)
+	}
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+):`)
+
+// tomlLinePattern matches the errors the TOML decoder returns for values that do not fit their field, which,
+// unlike syntax errors, are not returned as toml.ParseError.
+var tomlLinePattern = regexp.MustCompile(`^toml: line (\d+) \(last key "([^"]*)"\)`)
+
+// newDecodeError wraps the error of a deserializer into a DecodeError, extracting the position of the error
+// for the formats that report it.
+func newDecodeError(file string, data []byte, err error) *DecodeError {
+	decodeErr := &DecodeError{File: file, Err: err}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var parseErr toml.ParseError
+	var yamlErr *yaml.TypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset is the number of bytes read, including the offending one.
+		position := offsetPosition(data, syntaxErr.Offset-1)
+		decodeErr.Line, decodeErr.Column = position.Line, position.Column
+	case errors.As(err, &typeErr):
+		position := offsetPosition(data, typeErr.Offset)
+		if typeErr.Field != "" {
+			if positions := locateKeys(new(JSONDeserializer), data); positions != nil {
+				if keyPosition, ok := positions.positions[strings.ToLower(typeErr.Field)]; ok {
+					position = keyPosition
+				}
+			}
+			decodeErr.Err = &FieldError{Path: typeErr.Field, Expected: typeErr.Type.String(), Actual: typeErr.Value,
+				Err: err}
+		}
+		decodeErr.Line, decodeErr.Column = position.Line, position.Column
+	case errors.As(err, &parseErr):
+		position := offsetPosition(data, int64(parseErr.Position.Start))
+		decodeErr.Line, decodeErr.Column = parseErr.Position.Line, position.Column
+	case tomlLinePattern.MatchString(err.Error()):
+		match := tomlLinePattern.FindStringSubmatch(err.Error())
+		decodeErr.Line, _ = strconv.Atoi(match[1])
+		decodeErr.Column = tomlKeyColumn(data, decodeErr.Line, match[2])
+	case errors.As(err, &yamlErr) || strings.HasPrefix(err.Error(), "yaml: "):
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			decodeErr.Line, _ = strconv.Atoi(match[1])
+		}
+	}
+	return decodeErr
+}
+
+// tomlKeyColumn returns the column of the last segment of the given dotted key within the given line of data,
+// or the column of the first non-blank character of the line if the key cannot be found.
+func tomlKeyColumn(data []byte, line int, key string) int {
+	lines := bytes.Split(data, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return 0
+	}
+	text := lines[line-1]
+	if i := bytes.Index(text, []byte(key[strings.LastIndex(key, ".")+1:])); i >= 0 && key != "" {
+		return i + 1
+	}
+	return len(text) - len(bytes.TrimLeft(text, " \t")) + 1
+}
@@ -0,0 +1,116 @@
+package configloader
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+type ErrorsConfig struct {
+	Name     string `yaml:"name" json:"name" toml:"name"`
+	Port     int    `yaml:"port" json:"port" toml:"port" default:"80"`
+	Database struct {
+		Port int `yaml:"port" json:"port" toml:"port"`
+	} `yaml:"database" json:"database" toml:"database"`
+}
+
+func TestSourceNotFoundError(t *testing.T) {
+	dir := t.TempDir()
+	var config ErrorsConfig
+	err := NewConfigLoader("missing.yaml", WithPath(dir)).Load(&config)
+
+	var notFound *SourceNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected a SourceNotFoundError; got: %v", err)
+	}
+	if notFound.Source != filepath.Join(dir, "missing.yaml") {
+		t.Errorf("Source is not correct; got: %s", notFound.Source)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the error to match fs.ErrNotExist; got: %v", err)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		data   string
+		line   int
+		column int
+		field  *FieldError
+	}{
+		{name: "JSONSyntax", file: "config.json", data: "{\n  \"name\": \"app\",\n  \"port\" 80\n}", line: 3, column: 10},
+		{
+			name: "JSONType", file: "config.json", data: "{\n  \"database\": {\n    \"port\": \"high\"\n  }\n}", line: 3, column: 5,
+			field: &FieldError{Path: "database.port", Expected: "int", Actual: "string"},
+		},
+		{name: "YAML", file: "config.yaml", data: "name: app\nport: [\n", line: 2},
+		{name: "TOML", file: "config.toml", data: "name = \"app\"\nport = = 80\n", line: 2, column: 8},
+		{name: "TOMLType", file: "config.toml", data: "name = \"app\"\n\n[database]\n  port = 'abc'\n", line: 4, column: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, tt.file), tt.data)
+
+			var config ErrorsConfig
+			err := NewConfigLoader(tt.file, WithPath(dir)).Load(&config)
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("Expected a DecodeError; got: %v", err)
+			}
+			if decodeErr.File != filepath.Join(dir, tt.file) || decodeErr.Line != tt.line || decodeErr.Column != tt.column {
+				t.Errorf("Position is not correct; got: %s:%d:%d", decodeErr.File, decodeErr.Line, decodeErr.Column)
+			}
+
+			if tt.field == nil {
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Expected a FieldError; got: %v", err)
+			}
+			if fieldErr.Path != tt.field.Path || fieldErr.Expected != tt.field.Expected || fieldErr.Actual != tt.field.Actual {
+				t.Errorf("Field error is not correct; got: %+v", fieldErr)
+			}
+		})
+	}
+}
+
+func TestFieldErrorsAreJoined(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: app\n")
+
+	loader := NewConfigLoader("config.yaml", WithPath(dir))
+	loader.Override("Port", "high")
+	loader.Override("Missing", 1)
+
+	var config ErrorsConfig
+	err := loader.Load(&config)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("Expected two joined errors; got: %v", err)
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(joined.Unwrap()[0], &fieldErr) || fieldErr.Path != "Missing" {
+		t.Errorf("Expected a FieldError for Missing; got: %v", joined.Unwrap()[0])
+	}
+	if !errors.As(joined.Unwrap()[1], &fieldErr) || fieldErr.Path != "Port" || fieldErr.Expected != "int" ||
+		fieldErr.Actual != "string" {
+		t.Errorf("Expected a FieldError for Port; got: %+v", fieldErr)
+	}
+}
+
+func TestDefaultsFieldError(t *testing.T) {
+	var config struct {
+		Port int `default:"high"`
+	}
+	var fieldErr *FieldError
+	if err := ApplyDefaults(&config); !errors.As(err, &fieldErr) || fieldErr.Path != "Port" {
+		t.Errorf("Expected a FieldError for Port; got: %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+package fieldsetter
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+	timeLayouts         = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+)
+
+// Convert turns value into a reflect.Value that can be assigned to a target of type t. Values that are
+// not directly assignable are coerced where possible:
+//
+//   - strings are parsed into strings, bools, all numeric kinds, time.Duration, time.Time, url.URL and any type
+//     implementing encoding.TextUnmarshaler (e.g. net.IP),
+//   - strings are split at commas into slices and arrays (e.g. "a,b,c"), and into maps of key=value pairs
+//     (e.g. "a=1,b=2"), converting each element by the rules above,
+//   - numbers are converted between all numeric kinds as long as the value can be represented exactly, except
+//     that float64 values are rounded to the nearest float32,
+//   - pointer targets are allocated and their element is set by the rules above.
+//
+// A nil value results in the zero value of t. These are the same rules SetValue applies. Conversion failures
+// are returned as a *FieldError with the expected and actual type set.
+func Convert(value any, t reflect.Type) (reflect.Value, error) {
+	return convertValue(value, t, "target")
+}
+
+// convertValue works like Convert. The description names the kind of target in error messages.
+func convertValue(value any, t reflect.Type, description string) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+	newValue := reflect.ValueOf(value)
+	if newValue.Type().AssignableTo(t) {
+		return newValue, nil
+	}
+	result, ok, err := coerce(newValue, t)
+	if err != nil {
+		err = fmt.Errorf("cannot convert value of type %s to %s type %s: %w", newValue.Type(), description, t, err)
+	} else if !ok {
+		err = fmt.Errorf("value type %s is not assignable to %s type %s", newValue.Type(), description, t)
+	}
+	if err != nil {
+		return reflect.Value{}, &FieldError{Expected: t.String(), Actual: newValue.Type().String(), Err: err}
+	}
+	return result, nil
+}
+
+// coerce converts v into a value of type t. The boolean result reports whether a conversion rule exists for
+// the two types at all, while the error reports a failure in applying it.
+func coerce(v reflect.Value, t reflect.Type) (reflect.Value, bool, error) {
+	if t.Kind() == reflect.Pointer {
+		elem := v
+		if !v.Type().AssignableTo(t.Elem()) {
+			var ok bool
+			var err error
+			if elem, ok, err = coerce(v, t.Elem()); !ok || err != nil {
+				return reflect.Value{}, ok, err
+			}
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, true, nil
+	}
+	if v.Kind() == reflect.String {
+		return parseString(v.String(), t)
+	}
+	if isNumber(v.Kind()) && isNumber(t.Kind()) {
+		result, err := convertNumber(v, t)
+		return result, true, err
+	}
+	return reflect.Value{}, false, nil
+}
+
+// parseString parses s into a new value of type t.
+func parseString(s string, t reflect.Type) (reflect.Value, bool, error) {
+	result := reflect.New(t).Elem()
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		result.SetInt(int64(d))
+		return result, true, nil
+	case t == timeType:
+		for _, layout := range timeLayouts {
+			if parsed, err := time.Parse(layout, s); err == nil {
+				result.Set(reflect.ValueOf(parsed))
+				return result, true, nil
+			}
+		}
+		return reflect.Value{}, true, fmt.Errorf("cannot parse %q as time", s)
+	case t == urlType:
+		u, err := url.Parse(s)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		result.Set(reflect.ValueOf(u).Elem())
+		return result, true, nil
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		if err := result.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, true, err
+		}
+		return result, true, nil
+	}
+
+	var err error
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return parseList(s, t)
+	case reflect.String:
+		result.SetString(s)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		result.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 0, t.Bits())
+		result.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		u, err = strconv.ParseUint(s, 0, t.Bits())
+		result.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		result.SetFloat(f)
+	default:
+		return reflect.Value{}, false, nil
+	}
+	if err != nil {
+		return reflect.Value{}, true, err
+	}
+	return result, true, nil
+}
+
+// parseList parses a comma separated list into a slice, array or map of type t. Map entries are given as
+// key=value pairs. Byte slices are set to the bytes of s instead.
+func parseList(s string, t reflect.Type) (reflect.Value, bool, error) {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return reflect.ValueOf([]byte(s)).Convert(t), true, nil
+	}
+
+	var items []string
+	if strings.TrimSpace(s) != "" {
+		items = strings.Split(s, ",")
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		result := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			elem, err := convertValue(strings.TrimSpace(item), t.Elem(), "element")
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			result.Index(i).Set(elem)
+		}
+		return result, true, nil
+	case reflect.Array:
+		if len(items) > t.Len() {
+			return reflect.Value{}, true, fmt.Errorf("%d elements do not fit into %s", len(items), t)
+		}
+		result := reflect.New(t).Elem()
+		for i, item := range items {
+			elem, err := convertValue(strings.TrimSpace(item), t.Elem(), "element")
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			result.Index(i).Set(elem)
+		}
+		return result, true, nil
+	default:
+		result := reflect.MakeMapWithSize(t, len(items))
+		for _, item := range items {
+			k, v, ok := strings.Cut(item, "=")
+			if !ok {
+				return reflect.Value{}, true, fmt.Errorf("map entry %q is not a key=value pair", item)
+			}
+			key, err := convertValue(strings.TrimSpace(k), t.Key(), "map key")
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			elem, err := convertValue(strings.TrimSpace(v), t.Elem(), "map value")
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			result.SetMapIndex(key, elem)
+		}
+		return result, true, nil
+	}
+}
+
+// convertNumber converts the numeric value v into a value of the numeric type t, failing if the value cannot
+// be represented exactly. The only exception are conversions between float types, which round float64 values
+// to the nearest float32 like the decoders do.
+func convertNumber(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	result := reflect.New(t).Elem()
+	switch {
+	case isInt(t.Kind()):
+		var i int64
+		switch {
+		case isInt(v.Kind()):
+			i = v.Int()
+		case isUint(v.Kind()):
+			if v.Uint() > math.MaxInt64 {
+				return reflect.Value{}, fmt.Errorf("value %d overflows %s", v.Uint(), t)
+			}
+			i = int64(v.Uint())
+		default:
+			f := v.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return reflect.Value{}, fmt.Errorf("value %v cannot be represented as %s", f, t)
+			}
+			i = int64(f)
+		}
+		if result.OverflowInt(i) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", i, t)
+		}
+		result.SetInt(i)
+	case isUint(t.Kind()):
+		var u uint64
+		switch {
+		case isInt(v.Kind()):
+			if v.Int() < 0 {
+				return reflect.Value{}, fmt.Errorf("value %d cannot be represented as %s", v.Int(), t)
+			}
+			u = uint64(v.Int())
+		case isUint(v.Kind()):
+			u = v.Uint()
+		default:
+			f := v.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return reflect.Value{}, fmt.Errorf("value %v cannot be represented as %s", f, t)
+			}
+			u = uint64(f)
+		}
+		if result.OverflowUint(u) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", u, t)
+		}
+		result.SetUint(u)
+	default:
+		var f float64
+		switch {
+		case isInt(v.Kind()):
+			f = float64(v.Int())
+		case isUint(v.Kind()):
+			f = float64(v.Uint())
+		default:
+			f = v.Float()
+		}
+		if result.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", f, t)
+		}
+		result.SetFloat(f)
+		// Integers beyond the precision of the float type are rounded, which the round trip reveals. The
+		// bounds are checked first, as converting out of range floats to integers is implementation-defined.
+		stored := result.Float()
+		lossy := false
+		switch {
+		case isInt(v.Kind()):
+			lossy = stored < math.MinInt64 || stored >= math.MaxInt64 || int64(stored) != v.Int()
+		case isUint(v.Kind()):
+			lossy = stored >= math.MaxUint64 || uint64(stored) != v.Uint()
+		}
+		if lossy {
+			return reflect.Value{}, fmt.Errorf("value %v cannot be represented exactly as %s", v.Interface(), t)
+		}
+	}
+	return result, nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return isInt(kind) || isUint(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUint(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
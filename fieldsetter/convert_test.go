@@ -0,0 +1,129 @@
+package fieldsetter
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ConvertObject struct {
+	Int      int
+	Int8     int8
+	Int64    int64
+	Uint16   uint16
+	Float32  float32
+	Float64  float64
+	Bool     bool
+	Duration time.Duration
+	Time     time.Time
+	IP       net.IP
+	URL      url.URL
+	URLPtr   *url.URL
+	IntPtr   *int
+	Level    Level
+}
+
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return &net.ParseError{Type: "level", Text: string(text)}
+	}
+	return nil
+}
+
+func TestSetValueConversion(t *testing.T) {
+	intValue := 7
+	tests := []struct {
+		name    string
+		path    string
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{name: "String to int", path: "Int", value: "8080", want: 8080},
+		{name: "Hex string to int", path: "Int", value: "0x10", want: 16},
+		{name: "String to int8 overflow", path: "Int8", value: "300", wantErr: true},
+		{name: "String to uint16", path: "Uint16", value: "443", want: uint16(443)},
+		{name: "String to float32", path: "Float32", value: "1.5", want: float32(1.5)},
+		{name: "String to bool", path: "Bool", value: "true", want: true},
+		{name: "Invalid string to bool", path: "Bool", value: "maybe", wantErr: true},
+		{name: "String to duration", path: "Duration", value: "1m30s", want: 90 * time.Second},
+		{name: "String to time", path: "Time", value: "2024-03-01T10:00:00Z", want: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{name: "Date string to time", path: "Time", value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "String to IP", path: "IP", value: "10.0.0.1", want: net.ParseIP("10.0.0.1")},
+		{name: "Invalid string to IP", path: "IP", value: "not-an-ip", wantErr: true},
+		{name: "String to URL", path: "URL", value: "https://example.com/path", want: url.URL{Scheme: "https", Host: "example.com", Path: "/path"}},
+		{name: "String to URL pointer", path: "URLPtr", value: "https://example.com", want: &url.URL{Scheme: "https", Host: "example.com"}},
+		{name: "String to TextUnmarshaler", path: "Level", value: "info", want: Level(1)},
+		{name: "String to int pointer", path: "IntPtr", value: "7", want: &intValue},
+		{name: "Int to int64", path: "Int64", value: 42, want: int64(42)},
+		{name: "Int64 to int8", path: "Int8", value: int64(12), want: int8(12)},
+		{name: "Int to int8 overflow", path: "Int8", value: 1000, wantErr: true},
+		{name: "Negative int to uint16", path: "Uint16", value: -1, wantErr: true},
+		{name: "Int to float32", path: "Float32", value: 2, want: float32(2)},
+		{name: "Inexact int to float32", path: "Float32", value: 16777217, wantErr: true},
+		{name: "Inexact int64 to float64", path: "Float64", value: int64(1<<53 + 1), wantErr: true},
+		{name: "Inexact uint64 to float64", path: "Float64", value: uint64(1<<64 - 1), wantErr: true},
+		{name: "Large exact int64 to float64", path: "Float64", value: int64(1 << 62), want: float64(1 << 62)},
+		{name: "Float64 to float32", path: "Float32", value: 0.1, want: float32(0.1)},
+		{name: "Whole float to int", path: "Int", value: 3.0, want: 3},
+		{name: "Fractional float to int", path: "Int", value: 3.5, wantErr: true},
+		{name: "Int to int pointer", path: "IntPtr", value: 7, want: &intValue},
+		{name: "Bool to int", path: "Int", value: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &ConvertObject{}
+			err := SetValue(obj, tt.path, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := reflect.ValueOf(obj).Elem().FieldByName(tt.path).Interface()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetValue() set %s to %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    any
+		wantErr bool
+	}{
+		{name: "String slice", value: "a, b,c", want: []string{"a", "b", "c"}},
+		{name: "Empty slice", value: "", want: []int{}},
+		{name: "Int slice", value: "1,2,3", want: []int{1, 2, 3}},
+		{name: "Invalid int slice", value: "1,two", want: []int{}, wantErr: true},
+		{name: "Duration array", value: "1s,2s", want: [3]time.Duration{time.Second, 2 * time.Second}},
+		{name: "Array overflow", value: "1,2,3", want: [2]int{}, wantErr: true},
+		{name: "Byte slice", value: "raw,bytes", want: []byte("raw,bytes")},
+		{name: "Map", value: "a=1, b=2", want: map[string]int{"a": 1, "b": 2}},
+		{name: "Map with int keys", value: "80=http,443=https", want: map[int]string{80: "http", 443: "https"}},
+		{name: "Invalid map entry", value: "a", want: map[string]int{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.value, reflect.TypeOf(tt.want))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got.Interface(), tt.want) {
+				t.Errorf("Convert() = %#v, want %#v", got.Interface(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package fieldsetter
+
+import "fmt"
+
+// FieldError describes why a value could not be set at a field path. Path is the path as given to SetValue,
+// and is empty for errors returned by Convert. Expected and Actual name the type of the target and the type
+// of the value if the error was caused by a failed conversion, and are empty otherwise. Err holds the
+// underlying error.
+type FieldError struct {
+	Path     string
+	Expected string
+	Actual   string
+	Err      error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -4,18 +4,49 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// Setter controls how the segments of a field path are resolved to struct fields. The zero value requires
+// every segment to exactly match a struct field name, including capitalization, which is the behavior of
+// the package level SetFields and SetValue functions.
+//
+// If Tag is set, a segment also matches a field whose tag of that name (e.g. "yaml", "json", "toml", "env"
+// or "config") names the segment, so that the same keys can be used as in the configuration files. If
+// CaseInsensitive is set, segments that do not match any field exactly are matched ignoring case. A segment
+// matching more than one field results in an ambiguity error.
+//
+// Nil pointers and maps along a path are always allocated. If GrowSlices is set, slices that are too short
+// for an index in the path are grown to fit it, with the new elements set to their zero value. As the
+// indices usually come from untrusted input like environment variables, slices are only grown up to
+// MaxGrowLen elements; larger indices result in an error.
+type Setter struct {
+	Tag             string
+	CaseInsensitive bool
+	GrowSlices      bool
+}
+
+// MaxGrowLen is the length up to which a Setter with GrowSlices set grows slices.
+const MaxGrowLen = 1024
+
 // SetFields updates the fields of the given object based on a map of field paths to values.
 // Field names in the path must exactly match the struct field names, including capitalization.
+// The fields are set in a deterministic order: paths with fewer segments are set before paths with more
+// segments, and paths of equal length are set in lexical order. This way a parent always is set before
+// its children, so that e.g. "Nested.Field" takes precedence over the value given for "Nested".
 // It optionally continues on error when soft is true, collecting all errors encountered.
 // Returns a slice of errors encountered during the update, or nil if no errors occurred.
 func SetFields(obj any, fields map[string]any, soft bool) []error {
+	return Setter{}.SetFields(obj, fields, soft)
+}
+
+// SetFields works like the package level SetFields, resolving path segments according to the Setter.
+func (s Setter) SetFields(obj any, fields map[string]any, soft bool) []error {
 	var errs []error
-	for path, value := range fields {
-		err := SetValue(obj, path, value)
+	for _, path := range sortPaths(fields) {
+		err := s.SetValue(obj, path, fields[path])
 		if err != nil {
 			if soft {
 				errs = append(errs, err)
@@ -31,24 +62,79 @@ func SetFields(obj any, fields map[string]any, soft bool) []error {
 	}
 }
 
+// sortPaths returns the paths of the given fields in the order SetFields applies them: parents before
+// children, and paths of the same depth in lexical order.
+func sortPaths(fields map[string]any) []string {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "."), strings.Count(paths[j], ".")
+		if di != dj {
+			return di < dj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
 // SetValue updates a specific field of an object based on the given field path and value.
 // The object must be a pointer, and the field path must exactly match the struct's field names,
 // including capitalization. Supports nested fields, arrays, slices, and maps. For arrays and slices,
 // the path segment should include the index directly following the field name (e.g., "ArrayField.0").
-// For maps, it should include the key directly following the field name (e.g., "MapField.Key").
+// For maps, it should include the key directly following the field name (e.g., "MapField.Key"), which is
+// converted to the key type of the map, and may be followed by a path into the map value
+// (e.g., "MapField.Key.Field").
+// Nil pointers and maps along the path are allocated as needed.
+// Values that are not assignable to the target type are converted where possible: strings are parsed into
+// numbers, bools, time.Duration, time.Time, url.URL and encoding.TextUnmarshaler implementations, and
+// numbers are converted between numeric kinds if they can be represented exactly.
 // Returns an error if the object is not a pointer, the path is invalid, the specified index is out of
-// bounds, the key does not exist in the map, or if the value type is incompatible with the field,
-// array element, or map value type.
+// bounds, or if the value cannot be converted to the field,
+// array element, or map value type. Errors are returned as a *FieldError carrying the path.
 func SetValue(obj any, path string, value any) error {
+	return Setter{}.SetValue(obj, path, value)
+}
+
+// SetValue works like the package level SetValue, resolving path segments according to the Setter.
+func (s Setter) SetValue(obj any, path string, value any) error {
+	return s.SetPath(obj, strings.Split(path, "."), value)
+}
+
+// SetPath updates a specific field of an object like SetValue, taking the path as its individual segments.
+// Unlike a dotted path, the segments may contain dots themselves, which allows setting map keys like
+// "app.kubernetes.io/name".
+func SetPath(obj any, path []string, value any) error {
+	return Setter{}.SetPath(obj, path, value)
+}
+
+// SetPath works like the package level SetPath, resolving path segments according to the Setter.
+func (s Setter) SetPath(obj any, pathSegments []string, value any) error {
 	v := reflect.ValueOf(obj)
 	if v.Kind() != reflect.Ptr {
 		return errors.New("Object must be a pointer")
 	}
-	pathSegments := strings.Split(path, ".")
-	return setFieldRecursive(v, pathSegments, value)
+	path := strings.Join(pathSegments, ".")
+	err := s.setFieldRecursive(v, pathSegments, value)
+	if err == nil {
+		return nil
+	}
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return &FieldError{Path: path, Expected: fieldErr.Expected, Actual: fieldErr.Actual, Err: fieldErr.Err}
+	}
+	return &FieldError{Path: path, Err: err}
+}
+
+// SetString updates a specific field of an object like SetValue, taking the value as a string. This is a
+// convenience for values coming from textual sources like environment variables or command line flags,
+// which are parsed into the type of the targeted field by the same rules SetValue applies.
+func SetString(obj any, path string, value string) error {
+	return SetValue(obj, path, value)
 }
 
-func setFieldRecursive(v reflect.Value, pathSegments []string, value any) error {
+func (s Setter) setFieldRecursive(v reflect.Value, pathSegments []string, value any) error {
 	if len(pathSegments) == 0 {
 		return fmt.Errorf("no path segments provided")
 	}
@@ -57,29 +143,31 @@ func setFieldRecursive(v reflect.Value, pathSegments []string, value any) error
 		return errors.New("target must be a pointer and settable")
 	}
 	v = v.Elem()
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
 
 	switch v.Kind() {
 	case reflect.Struct:
-		field := v.FieldByName(pathSegments[0])
-		if !field.IsValid() {
-			return fmt.Errorf("field %s does not exist", pathSegments[0])
+		field, err := s.lookupField(v, pathSegments[0])
+		if err != nil {
+			return err
 		}
 		if !field.CanSet() {
 			return fmt.Errorf("cannot set field %s", pathSegments[0])
 		}
 		if len(pathSegments) == 1 {
-			if value == nil {
-				field.Set(reflect.Zero(field.Type()))
-				return nil
+			fieldValue, err := convertValue(value, field.Type(), "field")
+			if err != nil {
+				return err
 			}
-			fieldValue := reflect.ValueOf(value)
-			if fieldValue.Type().AssignableTo(field.Type()) {
-				field.Set(fieldValue)
-				return nil
-			}
-			return fmt.Errorf("value type %s is not assignable to field type %s", fieldValue.Type(), field.Type())
+			field.Set(fieldValue)
+			return nil
 		}
-		return setFieldRecursive(field.Addr(), pathSegments[1:], value)
+		return s.setFieldRecursive(field.Addr(), pathSegments[1:], value)
 	case reflect.Slice, reflect.Array:
 		if len(pathSegments) < 1 {
 			return fmt.Errorf("no index provided for slice/array")
@@ -88,31 +176,121 @@ func setFieldRecursive(v reflect.Value, pathSegments []string, value any) error
 		if err != nil {
 			return fmt.Errorf("invalid index: %s", pathSegments[0])
 		}
+		if index >= v.Len() && s.GrowSlices && v.Kind() == reflect.Slice {
+			if index >= MaxGrowLen {
+				return fmt.Errorf("index %d exceeds the maximum slice length of %d", index, MaxGrowLen)
+			}
+			v.Set(reflect.AppendSlice(v, reflect.MakeSlice(v.Type(), index+1-v.Len(), index+1-v.Len())))
+		}
 		if index < 0 || index >= v.Len() {
 			return fmt.Errorf("index out of range: %d", index)
 		}
 		if len(pathSegments) == 1 {
 			elem := v.Index(index)
-			newValue := reflect.ValueOf(value)
-			if newValue.Type().AssignableTo(elem.Type()) {
-				elem.Set(newValue)
-				return nil
+			newValue, err := convertValue(value, elem.Type(), "element")
+			if err != nil {
+				return err
 			}
-			return fmt.Errorf("value type %s is not assignable to element type %s", newValue.Type(), elem.Type())
+			elem.Set(newValue)
+			return nil
 		}
-		return setFieldRecursive(v.Index(index).Addr(), pathSegments[1:], value)
+		return s.setFieldRecursive(v.Index(index).Addr(), pathSegments[1:], value)
 	case reflect.Map:
 		if len(pathSegments) < 1 {
 			return fmt.Errorf("no key provided for map")
 		}
-		key := reflect.ValueOf(pathSegments[0])
-		newValue := reflect.ValueOf(value)
-		if !newValue.Type().AssignableTo(v.Type().Elem()) {
-			return fmt.Errorf("value type %s is not assignable to map value type %s", newValue.Type(), v.Type().Elem())
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
 		}
-		v.SetMapIndex(key, newValue)
+		key, err := convertValue(pathSegments[0], v.Type().Key(), "map key")
+		if err != nil {
+			return err
+		}
+		if len(pathSegments) == 1 {
+			newValue, err := convertValue(value, v.Type().Elem(), "map value")
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(key, newValue)
+			return nil
+		}
+		// Map values are not addressable, so the value is copied, modified and stored back.
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := s.setFieldRecursive(elem.Addr(), pathSegments[1:], value); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
 		return nil
 	default:
 		return fmt.Errorf("unsupported type %s", v.Kind())
 	}
 }
+
+// lookupField returns the field of the struct v that is addressed by the path segment name. Exact matches of
+// the field name or, if configured, the tag name take precedence over case-insensitive matches.
+func (s Setter) lookupField(v reflect.Value, name string) (reflect.Value, error) {
+	if s.Tag == "" && !s.CaseInsensitive {
+		field, ok := v.Type().FieldByName(name)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("field %s does not exist", name)
+		}
+		return fieldByIndex(v, field.Index)
+	}
+
+	fields := reflect.VisibleFields(v.Type())
+	matchers := []func(a, b string) bool{func(a, b string) bool { return a == b }}
+	if s.CaseInsensitive {
+		matchers = append(matchers, strings.EqualFold)
+	}
+	for _, match := range matchers {
+		var matches []reflect.StructField
+		for _, field := range fields {
+			if match(field.Name, name) || s.matchesTag(field, name, match) {
+				matches = append(matches, field)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return fieldByIndex(v, matches[0].Index)
+		default:
+			names := make([]string, len(matches))
+			for i, match := range matches {
+				names[i] = match.Name
+			}
+			return reflect.Value{}, fmt.Errorf("field %s is ambiguous, it matches the fields %s", name, strings.Join(names, ", "))
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("field %s does not exist", name)
+}
+
+// fieldByIndex returns the nested field of the struct v with the given index sequence, allocating nil pointers
+// to embedded structs along the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot allocate embedded struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// matchesTag reports whether the tag configured for the Setter names the field name.
+func (s Setter) matchesTag(field reflect.StructField, name string, match func(a, b string) bool) bool {
+	if s.Tag == "" {
+		return false
+	}
+	tagName, _, _ := strings.Cut(field.Tag.Get(s.Tag), ",")
+	return tagName != "" && tagName != "-" && match(tagName, name)
+}
@@ -1,6 +1,7 @@
 package fieldsetter
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -203,3 +204,355 @@ func TestSetValue(t *testing.T) {
 		})
 	}
 }
+
+func TestSetString(t *testing.T) {
+	testObject := &TestObject{}
+
+	if err := SetString(testObject, "IntField", "42"); err != nil {
+		t.Fatalf("SetString() error = %v", err)
+	}
+	if testObject.IntField != 42 {
+		t.Errorf("expected IntField to be 42, got %d", testObject.IntField)
+	}
+	if err := SetString(testObject, "BoolField", "true"); err != nil {
+		t.Fatalf("SetString() error = %v", err)
+	}
+	if !testObject.BoolField {
+		t.Errorf("expected BoolField to be true, got %t", testObject.BoolField)
+	}
+	if err := SetString(testObject, "PointerField", "other"); err != nil {
+		t.Fatalf("SetString() error = %v", err)
+	}
+	if testObject.PointerField == nil || *testObject.PointerField != "other" {
+		t.Errorf("expected PointerField to be 'other', got %v", testObject.PointerField)
+	}
+	if err := SetString(testObject, "FloatField", "pi"); err == nil {
+		t.Errorf("expected an error when parsing an invalid float")
+	}
+}
+
+type TaggedObject struct {
+	Host    string `yaml:"host" json:"hostname"`
+	Port    int    `yaml:"port,omitempty"`
+	Ignored string `yaml:"-"`
+	Nested  struct {
+		Enabled bool `yaml:"enabled"`
+	} `yaml:"nested"`
+	URL string
+	Url string
+}
+
+func TestSetterTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		setter  Setter
+		path    string
+		value   any
+		want    func(obj *TaggedObject) bool
+		wantErr bool
+	}{
+		{
+			name:   "Field name without tag",
+			setter: Setter{},
+			path:   "Host",
+			value:  "a",
+			want:   func(obj *TaggedObject) bool { return obj.Host == "a" },
+		},
+		{
+			name:    "Tag name without tag setter",
+			setter:  Setter{},
+			path:    "host",
+			value:   "a",
+			wantErr: true,
+		},
+		{
+			name:   "YAML tag name",
+			setter: Setter{Tag: "yaml"},
+			path:   "host",
+			value:  "b",
+			want:   func(obj *TaggedObject) bool { return obj.Host == "b" },
+		},
+		{
+			name:   "JSON tag name",
+			setter: Setter{Tag: "json"},
+			path:   "hostname",
+			value:  "c",
+			want:   func(obj *TaggedObject) bool { return obj.Host == "c" },
+		},
+		{
+			name:   "Tag name with options",
+			setter: Setter{Tag: "yaml"},
+			path:   "port",
+			value:  8080,
+			want:   func(obj *TaggedObject) bool { return obj.Port == 8080 },
+		},
+		{
+			name:   "Nested tag names",
+			setter: Setter{Tag: "yaml"},
+			path:   "nested.enabled",
+			value:  true,
+			want:   func(obj *TaggedObject) bool { return obj.Nested.Enabled },
+		},
+		{
+			name:   "Field name with tag setter",
+			setter: Setter{Tag: "yaml"},
+			path:   "Nested.Enabled",
+			value:  true,
+			want:   func(obj *TaggedObject) bool { return obj.Nested.Enabled },
+		},
+		{
+			name:    "Skipped tag",
+			setter:  Setter{Tag: "yaml"},
+			path:    "-",
+			value:   "x",
+			wantErr: true,
+		},
+		{
+			name:    "Case mismatch without case insensitivity",
+			setter:  Setter{Tag: "yaml"},
+			path:    "HOST",
+			value:   "d",
+			wantErr: true,
+		},
+		{
+			name:   "Case insensitive",
+			setter: Setter{Tag: "yaml", CaseInsensitive: true},
+			path:   "HOST",
+			value:  "d",
+			want:   func(obj *TaggedObject) bool { return obj.Host == "d" },
+		},
+		{
+			name:   "Exact match takes precedence",
+			setter: Setter{CaseInsensitive: true},
+			path:   "URL",
+			value:  "e",
+			want:   func(obj *TaggedObject) bool { return obj.URL == "e" && obj.Url == "" },
+		},
+		{
+			name:    "Ambiguous case insensitive match",
+			setter:  Setter{CaseInsensitive: true},
+			path:    "url",
+			value:   "f",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &TaggedObject{}
+			err := tt.setter.SetValue(obj, tt.path, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !tt.want(obj) {
+				t.Errorf("SetValue() did not set %s correctly, got %+v", tt.path, obj)
+			}
+		})
+	}
+}
+
+func TestSetFieldsOrder(t *testing.T) {
+	type Nested struct {
+		StringField string
+		IntField    int
+	}
+	type Object struct {
+		Nested Nested
+	}
+
+	fields := map[string]any{
+		"Nested.IntField":    2,
+		"Nested":             Nested{StringField: "parent", IntField: 1},
+		"Nested.StringField": "child",
+	}
+	for i := 0; i < 50; i++ {
+		obj := &Object{}
+		if errs := SetFields(obj, fields, false); errs != nil {
+			t.Fatalf("SetFields() errors = %v", errs)
+		}
+		if obj.Nested.StringField != "child" || obj.Nested.IntField != 2 {
+			t.Fatalf("SetFields() did not apply parents before children, got %+v", obj.Nested)
+		}
+	}
+}
+
+type Replica struct {
+	Host string
+}
+
+type Database struct {
+	Primary  *Replica
+	Replicas []Replica
+	Options  map[string]string
+}
+
+type AllocObject struct {
+	*Replica
+	DB *Database
+}
+
+func TestSetValueAllocation(t *testing.T) {
+	obj := &AllocObject{}
+
+	if err := SetValue(obj, "DB.Primary.Host", "primary"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.DB == nil || obj.DB.Primary == nil || obj.DB.Primary.Host != "primary" {
+		t.Fatalf("expected DB.Primary.Host to be 'primary', got %+v", obj.DB)
+	}
+
+	if err := SetValue(obj, "DB.Options.timeout", "5s"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.DB.Options["timeout"] != "5s" {
+		t.Errorf("expected DB.Options[\"timeout\"] to be '5s', got %v", obj.DB.Options)
+	}
+
+	if err := SetValue(obj, "Host", "embedded"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.Replica == nil || obj.Replica.Host != "embedded" {
+		t.Errorf("expected embedded Host to be 'embedded', got %+v", obj.Replica)
+	}
+
+	if err := SetValue(obj, "DB.Replicas.3.Host", "replica"); err == nil {
+		t.Errorf("expected an error for an index out of range without GrowSlices")
+	}
+
+	setter := Setter{GrowSlices: true}
+	if err := setter.SetValue(obj, "DB.Replicas.3.Host", "replica"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if len(obj.DB.Replicas) != 4 || obj.DB.Replicas[3].Host != "replica" {
+		t.Errorf("expected DB.Replicas to be grown to 4 elements, got %+v", obj.DB.Replicas)
+	}
+	if err := setter.SetValue(obj, "DB.Replicas.1.Host", "second"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if len(obj.DB.Replicas) != 4 || obj.DB.Replicas[1].Host != "second" {
+		t.Errorf("expected DB.Replicas[1] to be set without growing, got %+v", obj.DB.Replicas)
+	}
+	var fieldErr *FieldError
+	if err := setter.SetValue(obj, "DB.Replicas.1000000000.Host", "huge"); !errors.As(err, &fieldErr) {
+		t.Errorf("expected a FieldError for an index beyond MaxGrowLen, got %v", err)
+	}
+	if len(obj.DB.Replicas) != 4 {
+		t.Errorf("expected DB.Replicas not to be grown beyond MaxGrowLen, got %d elements", len(obj.DB.Replicas))
+	}
+}
+
+type Service struct {
+	Port int
+	Tags []string
+}
+
+type ServiceName string
+
+type MapObject struct {
+	Services        map[string]Service
+	ServicePointers map[string]*Service
+	Named           map[ServiceName]int
+	Ports           map[int]string
+	Levels          map[Level]string
+}
+
+func TestSetValueMapPaths(t *testing.T) {
+	obj := &MapObject{
+		Services: map[string]Service{"api": {Port: 80, Tags: []string{"public"}}},
+	}
+
+	if err := SetValue(obj, "Services.api.Port", 8080); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.Services["api"].Port != 8080 || len(obj.Services["api"].Tags) != 1 {
+		t.Errorf("expected only Services[\"api\"].Port to change, got %+v", obj.Services["api"])
+	}
+
+	if err := SetValue(obj, "Services.web.Port", "9090"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.Services["web"].Port != 9090 {
+		t.Errorf("expected Services[\"web\"].Port to be 9090, got %+v", obj.Services["web"])
+	}
+
+	if err := SetValue(obj, "ServicePointers.db.Port", 5432); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.ServicePointers["db"] == nil || obj.ServicePointers["db"].Port != 5432 {
+		t.Errorf("expected ServicePointers[\"db\"].Port to be 5432, got %+v", obj.ServicePointers["db"])
+	}
+	existing := obj.ServicePointers["db"]
+	if err := SetValue(obj, "ServicePointers.db.Tags", []string{"internal"}); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.ServicePointers["db"] != existing || existing.Port != 5432 || len(existing.Tags) != 1 {
+		t.Errorf("expected ServicePointers[\"db\"] to be modified in place, got %+v", obj.ServicePointers["db"])
+	}
+
+	if err := SetValue(obj, "Named.api", 1); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.Named[ServiceName("api")] != 1 {
+		t.Errorf("expected Named[\"api\"] to be 1, got %v", obj.Named)
+	}
+
+	if err := SetValue(obj, "Ports.443", "https"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.Ports[443] != "https" {
+		t.Errorf("expected Ports[443] to be 'https', got %v", obj.Ports)
+	}
+	if err := SetValue(obj, "Ports.https", "443"); err == nil {
+		t.Errorf("expected an error for a key that cannot be converted")
+	}
+
+	if err := SetValue(obj, "Levels.info", "verbose"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if obj.Levels[Level(1)] != "verbose" {
+		t.Errorf("expected Levels[info] to be 'verbose', got %v", obj.Levels)
+	}
+
+	if err := SetValue(obj, "Services.api.Missing", 1); err == nil {
+		t.Errorf("expected an error for a non-existent field in a map value")
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	obj := &MapObject{}
+	if err := SetPath(obj, []string{"Services", "api.example.com", "Port"}, 443); err != nil {
+		t.Fatalf("SetPath() error = %v", err)
+	}
+	if obj.Services["api.example.com"].Port != 443 {
+		t.Errorf("expected Services[\"api.example.com\"].Port to be 443, got %v", obj.Services)
+	}
+
+	var fieldErr *FieldError
+	err := SetPath(obj, []string{"Services", "a.b", "Missing"}, 1)
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Services.a.b.Missing" {
+		t.Errorf("expected a FieldError with the joined path, got %v", err)
+	}
+}
+
+func TestSetValueFieldError(t *testing.T) {
+	var obj ConvertObject
+	tests := []struct {
+		path     string
+		value    any
+		expected FieldError
+	}{
+		{path: "Int", value: "abc", expected: FieldError{Path: "Int", Expected: "int", Actual: "string"}},
+		{path: "Missing", value: 1, expected: FieldError{Path: "Missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var fieldErr *FieldError
+			if err := SetValue(&obj, tt.path, tt.value); !errors.As(err, &fieldErr) {
+				t.Fatalf("Expected a FieldError; got: %v", err)
+			}
+			if fieldErr.Path != tt.expected.Path || fieldErr.Expected != tt.expected.Expected ||
+				fieldErr.Actual != tt.expected.Actual || fieldErr.Err == nil {
+				t.Errorf("FieldError is not correct; got: %+v", fieldErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package configloader
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GlobSource is a Source that applies every file matching Pattern, like the files of a conf.d directory
+// (e.g. "/etc/myapp/conf.d/*.yaml"). The matching files are applied in lexical order of their paths, each
+// onto the result of the ones before it, so that nested structs and maps are merged and later files take
+// precedence. The format of each file is detected from its extension unless a Deserializer is set, and files
+// with an extension no deserializer is registered for, like editor backups (e.g. "10-db.yaml~"), are skipped
+// in that case. Directories matching the pattern are skipped as well. Files are matched in FS if it is set,
+// using the syntax of fs.Glob, and in the OS filesystem with the syntax of filepath.Glob otherwise. A pattern
+// without matches applies nothing. Strict works as it does for a FileSource.
+type GlobSource struct {
+	Pattern      string
+	Deserializer DeserializerFunc
+	FS           fs.FS
+	Strict       bool
+}
+
+// NewGlobSource creates a new GlobSource for the files matching the given pattern, which are interpreted using
+// the given deserializer. A nil deserializer selects one per file based on its extension.
+func NewGlobSource(pattern string, deserializer DeserializerFunc) *GlobSource {
+	return &GlobSource{
+		Pattern:      pattern,
+		Deserializer: deserializer,
+	}
+}
+
+// Files returns a FileSource for every file matching the pattern that is applied, in the order they are
+// applied.
+func (g *GlobSource) Files() ([]*FileSource, error) {
+	var matches []string
+	var err error
+	if g.FS != nil {
+		matches, err = fs.Glob(g.FS, g.Pattern)
+	} else {
+		matches, err = filepath.Glob(g.Pattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	files := make([]*FileSource, 0, len(matches))
+	for _, match := range matches {
+		if g.Deserializer == nil {
+			if _, ok := DeserializerForFile(match); !ok {
+				continue
+			}
+		}
+		var info fs.FileInfo
+		if g.FS != nil {
+			info, err = fs.Stat(g.FS, match)
+		} else {
+			info, err = os.Stat(match)
+		}
+		if err == nil && info.IsDir() {
+			continue
+		}
+		var file *FileSource
+		if g.FS != nil {
+			file = NewFileSource(path.Dir(match), path.Base(match), g.Deserializer)
+			file.FS = g.FS
+		} else {
+			file = NewFileSource(filepath.Dir(match), filepath.Base(match), g.Deserializer)
+		}
+		file.Strict = g.Strict
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// Apply applies all files matching the pattern onto the given config object.
+func (g *GlobSource) Apply(config any) error {
+	return g.ApplyContext(context.Background(), config)
+}
+
+// ApplyContext works like Apply, but stops waiting for the files to be read once the context is done.
+func (g *GlobSource) ApplyContext(ctx context.Context, config any) error {
+	for _, layer := range g.layers() {
+		if _, err := applySource(ctx, layer, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layers returns the matching files of the GlobSource as individual layers, so that the loader can record the
+// provenance of every file. An invalid pattern results in a single layer reporting the error.
+func (g *GlobSource) layers() []Source {
+	files, err := g.Files()
+	if err != nil {
+		return []Source{&failedSource{name: g.String(), err: err}}
+	}
+	layers := make([]Source, len(files))
+	for i, file := range files {
+		layers[i] = file
+	}
+	return layers
+}
+
+// String returns the pattern of the GlobSource.
+func (g *GlobSource) String() string {
+	return g.Pattern
+}
+
+// WatchPaths returns the directory of the pattern, so that added and removed files trigger a reload as well.
+// If the directory itself contains wildcards, the directories of the currently matching files are returned
+// instead. Nothing is returned if the GlobSource matches files in an FS.
+func (g *GlobSource) WatchPaths() []string {
+	if g.FS != nil {
+		return nil
+	}
+	if dir := filepath.Dir(g.Pattern); !strings.ContainsAny(dir, "*?[") {
+		return []string{dir}
+	}
+	files, _ := g.Files()
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, file := range files {
+		if !seen[file.Path] {
+			seen[file.Path] = true
+			dirs = append(dirs, file.Path)
+		}
+	}
+	return dirs
+}
+
+// failedSource is a layer that fails with the error that occurred while expanding a layered source.
+type failedSource struct {
+	name string
+	err  error
+}
+
+func (f *failedSource) Apply(config any) error {
+	return f.err
+}
+
+func (f *failedSource) String() string {
+	return f.name
+}
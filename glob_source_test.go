@@ -0,0 +1,105 @@
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+type GlobConfig struct {
+	Name     string `yaml:"name" json:"name" toml:"name"`
+	Database struct {
+		Host string `yaml:"host" json:"host" toml:"host"`
+		Port int    `yaml:"port" json:"port" toml:"port"`
+	} `yaml:"database" json:"database" toml:"database"`
+	Labels map[string]string `yaml:"labels" json:"labels" toml:"labels"`
+}
+
+func TestGlobSource(t *testing.T) {
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0o700); err != nil {
+		t.Fatalf("Unable to create %s: %s", confDir, err)
+	}
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: main\n")
+	writeFile(t, filepath.Join(confDir, "10-database.yaml"), "database:\n  host: db\n  port: 5432\nlabels:\n  team: core\n")
+	writeFile(t, filepath.Join(confDir, "20-port.json"), `{"database": {"port": 6432}, "labels": {"tier": "web"}}`)
+	writeFile(t, filepath.Join(confDir, "30-name.toml"), "name = \"glob\"\n")
+	writeFile(t, filepath.Join(confDir, "README"), "not a configuration file")
+
+	var config GlobConfig
+	loader := NewConfigLoader("config.yaml", WithPath(dir), WithGlob(filepath.Join(confDir, "*.*")))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.Name != "glob" || config.Database.Host != "db" || config.Database.Port != 6432 {
+		t.Errorf("Expected the files to be merged in order; got: %+v", config)
+	}
+	if config.Labels["team"] != "core" || config.Labels["tier"] != "web" {
+		t.Errorf("Expected the maps to be merged; got: %v", config.Labels)
+	}
+	if provenance, _ := loader.Explain("Database.Host"); provenance.Source != filepath.Join(confDir, "10-database.yaml") {
+		t.Errorf("Expected Database.Host to come from the first file; got: %s", provenance)
+	}
+	if paths := loader.watchPaths(); !slices.Contains(paths, confDir) {
+		t.Errorf("Expected the directory to be watched; got: %v", paths)
+	}
+}
+
+func TestGlobSourceFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf.d/b.yaml": {Data: []byte("name: b\n")},
+		"conf.d/a.yaml": {Data: []byte("name: a\ndatabase:\n  port: 1\n")},
+	}
+	var config GlobConfig
+	if err := (&GlobSource{Pattern: "conf.d/*.yaml", FS: fsys}).Apply(&config); err != nil {
+		t.Fatalf("Failed to apply glob source: %s", err)
+	}
+	if config.Name != "b" || config.Database.Port != 1 {
+		t.Errorf("Expected the files to be applied in lexical order; got: %+v", config)
+	}
+}
+
+func TestGlobSourceNoMatches(t *testing.T) {
+	var config GlobConfig
+	if err := NewGlobSource(filepath.Join(t.TempDir(), "conf.d", "*.yaml"), nil).Apply(&config); err != nil {
+		t.Errorf("Expected a pattern without matches to apply nothing; got: %s", err)
+	}
+	if err := NewGlobSource("[", nil).Apply(&config); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestGlobSourceSkipsUnsupported(t *testing.T) {
+	confDir := t.TempDir()
+	writeFile(t, filepath.Join(confDir, "10.yaml"), "name: first\n")
+	writeFile(t, filepath.Join(confDir, "10.yaml~"), "name: backup\n")
+	writeFile(t, filepath.Join(confDir, "20.yaml.swp"), "\x00binary")
+	if err := os.Mkdir(filepath.Join(confDir, "sub.yaml"), 0o700); err != nil {
+		t.Fatalf("Unable to create directory: %s", err)
+	}
+
+	var config GlobConfig
+	source := NewGlobSource(filepath.Join(confDir, "*"), nil)
+	if err := source.Apply(&config); err != nil {
+		t.Fatalf("Expected directories and unsupported files to be skipped; got: %s", err)
+	}
+	if config.Name != "first" {
+		t.Errorf("Expected only the YAML file to be applied; got: %+v", config)
+	}
+	if files, _ := source.Files(); len(files) != 1 {
+		t.Errorf("Expected a single file; got: %v", files)
+	}
+
+	fsys := fstest.MapFS{
+		"conf.d/a.yaml":       {Data: []byte("name: a\n")},
+		"conf.d/b.yaml~":      {Data: []byte("name: b\n")},
+		"conf.d/sub.yaml/c.x": {Data: []byte("ignored")},
+	}
+	config = GlobConfig{}
+	if err := (&GlobSource{Pattern: "conf.d/*", FS: fsys}).Apply(&config); err != nil || config.Name != "a" {
+		t.Errorf("Expected only a.yaml to be applied from the FS; got: %+v, %v", config, err)
+	}
+}
@@ -1,11 +1,15 @@
 package configloader
 
+import "context"
+
 // Loader defines the interface for loading configuration data into a Go struct. It requires implementation
-// of the Load method, which takes a configuration object and populates it with data, and the Override method,
+// of the LoadContext method, which takes a configuration object and populates it with data, aborting once the
+// context is done, the Load method, which does the same without a context, and the Override method,
 // which allows for dynamic modifications of specific configuration fields after the initial load. This interface
 // is designed to abstract the configuration loading mechanism, enabling the use of various sources and formats.
 type Loader interface {
 	Load(config any) error
+	LoadContext(ctx context.Context, config any) error
 	Override(path string, value any) error
 }
 
@@ -17,3 +21,38 @@ type Loader interface {
 type DeserializerFunc interface {
 	Deserialize(data []byte, v any) error
 }
+
+// ContextDeserializer can be implemented by deserializers that are able to abort deserialization once the
+// given context is done. Loaders prefer DeserializeContext over Deserialize when it is available.
+type ContextDeserializer interface {
+	DeserializeContext(ctx context.Context, data []byte, v any) error
+}
+
+// deserialize deserializes data into v with the given deserializer, passing on the context if the
+// deserializer supports it.
+func deserialize(ctx context.Context, deserializer DeserializerFunc, data []byte, v any) error {
+	if contextDeserializer, ok := deserializer.(ContextDeserializer); ok {
+		return contextDeserializer.DeserializeContext(ctx, data, v)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return deserializer.Deserialize(data, v)
+}
+
+// deserializeStrict works like deserialize, but uses strict deserialization if the deserializer supports it.
+// Deserializers that support a context, but not strict deserialization with a context, are only used strictly
+// if the context cannot be cancelled, so that cancellation always reaches them.
+func deserializeStrict(ctx context.Context, deserializer DeserializerFunc, data []byte, v any) error {
+	if contextStrict, ok := deserializer.(ContextStrictDeserializer); ok {
+		return contextStrict.DeserializeStrictContext(ctx, data, v)
+	}
+	strict, ok := deserializer.(StrictDeserializer)
+	if _, cancellable := deserializer.(ContextDeserializer); !ok || (cancellable && ctx.Done() != nil) {
+		return deserialize(ctx, deserializer, data, v)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return strict.DeserializeStrict(data, v)
+}
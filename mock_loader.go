@@ -1,10 +1,21 @@
 package configloader
 
-import "github.com/snippetaccumulator/configloader/fieldsetter"
+import (
+	"context"
+	"maps"
+	"sync"
 
+	"github.com/snippetaccumulator/configloader/fieldsetter"
+)
+
+// MockLoader is a Loader that sets the configuration from a map of field paths to values instead of reading
+// any files. FieldSetter controls how the paths of the mock data and overrides are resolved to fields.
+// Override, Overrides and Load are safe for concurrent use.
 type MockLoader struct {
-	MockData  map[string]any
-	overrides map[string]any
+	MockData    map[string]any
+	FieldSetter fieldsetter.Setter
+	mu          sync.RWMutex
+	overrides   map[string]any
 }
 
 // NewMockLoader creates an instance of MockLoader with predefined mock data. This mock loader is designed
@@ -18,18 +29,34 @@ func NewMockLoader(mockData map[string]any) *MockLoader {
 	}
 }
 
-// Load simulates the configuration loading process using predefined mock data. This method attempts to set
-// the configuration fields of the provided config object based on the mock data and any overrides that have
-// been specified. It is designed for testing, allowing developers to verify the behavior of their applications
-// with various configurations without needing to interact with actual configuration files.
+// Load simulates the configuration loading process using predefined mock data. This method applies the
+// defaults given by `default` struct tags and then attempts to set the configuration fields of the provided
+// config object based on the mock data and any overrides that have been specified. It is designed for testing,
+// allowing developers to verify the behavior of their applications with various configurations without needing
+// to interact with actual configuration files.
 // Returns the first error encountered during field setting if any, otherwise nil.
 func (m *MockLoader) Load(config any) error {
-	errs := fieldsetter.SetFields(config, m.MockData, false)
+	return m.LoadContext(context.Background(), config)
+}
+
+// LoadContext works like Load, but fails with the context's error if the given context is already done.
+func (m *MockLoader) LoadContext(ctx context.Context, config any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := applyDefaultsTo(config, scalarDefaults); err != nil {
+		return err
+	}
+
+	errs := m.FieldSetter.SetFields(config, m.MockData, false)
 	if len(errs) > 0 {
 		return errs[0]
 	}
+	if err := applyDefaultsTo(config, containerDefaults); err != nil {
+		return err
+	}
 
-	errs = fieldsetter.SetFields(config, m.overrides, true)
+	errs = m.FieldSetter.SetFields(config, m.Overrides(), true)
 	if len(errs) > 0 {
 		return errs[0]
 	}
@@ -41,6 +68,19 @@ func (m *MockLoader) Load(config any) error {
 // when specific fields are modified after the initial mock data load. It's particularly useful for validating
 // how applications handle dynamic configuration changes.
 func (m *MockLoader) Override(path string, value any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.overrides == nil {
+		m.overrides = make(map[string]any)
+	}
 	m.overrides[path] = value
 	return nil
 }
+
+// Overrides returns a snapshot of the overrides set on the MockLoader. Changes to the returned map do not
+// affect the MockLoader.
+func (m *MockLoader) Overrides() map[string]any {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.overrides)
+}
@@ -1,7 +1,10 @@
 package configloader
 
 import (
+	"sync"
 	"testing"
+
+	"github.com/snippetaccumulator/configloader/fieldsetter"
 )
 
 type Config struct {
@@ -164,6 +167,21 @@ func TestMockLoader_MockEmbeddedIndirectly(t *testing.T) {
 	}
 }
 
+func TestMockLoader_OverrideStructLiteral(t *testing.T) {
+	loader := &MockLoader{MockData: map[string]any{"Field1": "value1"}}
+	if err := loader.Override("Field2", 2); err != nil {
+		t.Fatalf("Failed to set override: %s", err)
+	}
+
+	var config Config
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.Field1 != "value1" || config.Field2 != 2 {
+		t.Fatalf("Configuration is not correct; got: %+v", config)
+	}
+}
+
 func TestMockLoader_OverrideEmbeddedDirectly(t *testing.T) {
 	mockData := map[string]interface{}{
 		"Field1":        "value1",
@@ -227,3 +245,64 @@ func TestMockLoader_OverrideEmbeddedIndirectly(t *testing.T) {
 		t.Fatalf("Field4 is not set correctly; expected: %f, got: %f", 3.14, config.Field4)
 	}
 }
+
+func TestMockLoader_FieldSetterTags(t *testing.T) {
+	mockData := map[string]interface{}{
+		"field1":        "value1",
+		"Field2":        2,
+		"nested.field3": true,
+	}
+
+	var config Config
+
+	loader := NewMockLoader(mockData)
+	loader.FieldSetter = fieldsetter.Setter{Tag: "yaml", CaseInsensitive: true}
+	loader.Override("FIELD1", "newvalue1")
+
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if config.Field1 != "newvalue1" {
+		t.Fatalf("Field1 is not set correctly; expected: %s, got: %s", "newvalue1", config.Field1)
+	}
+	if config.Field2 != 2 {
+		t.Fatalf("Field2 is not set correctly; expected: %d, got: %d", 2, config.Field2)
+	}
+	if config.Nested.Field3 != true {
+		t.Fatalf("Nested field is not set correctly; expected: %t, got: %t", true, config.Nested.Field3)
+	}
+}
+
+func TestMockLoader_ConcurrentOverrideAndLoad(t *testing.T) {
+	loader := NewMockLoader(map[string]interface{}{
+		"Field1": "value1",
+		"Field2": 2,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				loader.Override("Field2", i*100+j)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				var config Config
+				if err := loader.Load(&config); err != nil {
+					t.Errorf("Failed to load configuration: %s", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(loader.Overrides()) != 1 {
+		t.Fatalf("Expected a single override, got: %v", loader.Overrides())
+	}
+}
@@ -1,5 +1,11 @@
 package configloader
 
+import (
+	"io/fs"
+
+	"github.com/snippetaccumulator/configloader/fieldsetter"
+)
+
 // Option defines a function signature for optional configuration functions that customize the behavior of a ConfigLoader instance.
 // These functions enable flexible and modular configuration of a ConfigLoader by setting various parameters such as file paths,
 // deserializers, and override mechanisms. Each option function accepts a pointer to a ConfigLoader instance and modifies it
@@ -15,6 +21,31 @@ func WithPath(path string) Option {
 	}
 }
 
+// WithSearchPaths appends directories to search for the main configuration file, in order of precedence. Once
+// search paths are set, they are used instead of Path. By default the first directory containing the file is
+// used; see WithSearchMerge to merge the files of all directories.
+func WithSearchPaths(paths ...string) Option {
+	return func(loader *ConfigLoader) {
+		loader.SearchPaths = append(loader.SearchPaths, paths...)
+	}
+}
+
+// WithDefaultSearchPaths appends the conventional locations for the configuration of the named application,
+// as returned by DefaultSearchPaths, to the search paths. Further directories can be added after them with
+// WithSearchPaths.
+func WithDefaultSearchPaths(app string) Option {
+	return WithSearchPaths(DefaultSearchPaths(app)...)
+}
+
+// WithSearchMerge makes the ConfigLoader merge the main configuration files of all search paths instead of
+// using only the first match. The files are applied in reverse order, so that the directories listed first
+// take precedence, e.g. a file in the working directory over one in /etc/<app>.
+func WithSearchMerge() Option {
+	return func(loader *ConfigLoader) {
+		loader.SearchMerge = true
+	}
+}
+
 // WithOverrideFile sets the path and name for an override configuration file. This option function enables
 // the ConfigLoader to apply additional configuration settings from a specified file, allowing for flexible
 // adjustments and customization beyond the main configuration. The override file is processed after the
@@ -26,6 +57,58 @@ func WithOverrideFile(path, name string) Option {
 	}
 }
 
+// WithOptionalOverrideFile works like WithOverrideFile, but marks the override file as optional, so that Load
+// skips it if it does not exist instead of failing. This suits files only some environments provide, like a
+// developer's local.yaml.
+func WithOptionalOverrideFile(path, name string) Option {
+	return func(loader *ConfigLoader) {
+		WithOverrideFile(path, name)(loader)
+		loader.OverrideOptional = true
+	}
+}
+
+// WithOptionalFile marks the main configuration file as optional, so that Load skips it if it does not exist
+// and the configuration is built from the remaining layers alone.
+func WithOptionalFile() Option {
+	return func(loader *ConfigLoader) {
+		loader.Optional = true
+	}
+}
+
+// WithFS makes the ConfigLoader read the main configuration file from the given filesystem instead of the OS
+// filesystem, with Path and the search paths interpreted relative to its root. This allows loading a default
+// configuration compiled into the binary with go:embed, or testing against an fstest.MapFS. Combined with an
+// override file on disk, the embedded defaults can be adjusted per installation.
+func WithFS(fsys fs.FS) Option {
+	return func(loader *ConfigLoader) {
+		loader.FS = fsys
+	}
+}
+
+// WithOverrideFS makes the ConfigLoader read the override configuration file from the given filesystem
+// instead of the OS filesystem.
+func WithOverrideFS(fsys fs.FS) Option {
+	return func(loader *ConfigLoader) {
+		loader.OverrideFS = fsys
+	}
+}
+
+// WithMainSource replaces the main configuration file with the given source, e.g. a ReaderSource for
+// configuration data received over a pipe or in a request body. Name, Path and the search paths are ignored
+// in that case.
+func WithMainSource(source Source) Option {
+	return func(loader *ConfigLoader) {
+		loader.MainSource = source
+	}
+}
+
+// WithOverrideSource replaces the override configuration file with the given source.
+func WithOverrideSource(source Source) Option {
+	return func(loader *ConfigLoader) {
+		loader.OverrideSource = source
+	}
+}
+
 // WithDeserializer is an option function for ConfigLoader that sets the specified deserializer function
 // for interpreting the main configuration file. This allows for custom deserialization logic to be applied,
 // enabling the support of various data formats beyond the default ones provided.
@@ -44,3 +127,46 @@ func WithOverrideDeserializer(deserializer DeserializerFunc) Option {
 		loader.OverrideDeserializer = deserializer
 	}
 }
+
+// WithSource appends additional configuration layers to the ConfigLoader. Sources are applied after the main
+// and override configuration files, in the order they were added, so that later sources take precedence over
+// earlier ones. Programmatic overrides are always applied last.
+func WithSource(sources ...Source) Option {
+	return func(loader *ConfigLoader) {
+		loader.Sources = append(loader.Sources, sources...)
+	}
+}
+
+// WithEnvPrefix adds an EnvSource for environment variables with the given prefix to the ConfigLoader. Like
+// all sources, the environment variables are applied after the main and override configuration files, in the
+// order the options are given, and before the programmatic overrides.
+func WithEnvPrefix(prefix string) Option {
+	return WithSource(NewEnvSource(prefix))
+}
+
+// WithGlob adds a GlobSource for the files matching the given pattern to the ConfigLoader, e.g.
+// "/etc/myapp/conf.d/*.yaml". The matching files are applied in lexical order, with the format of each
+// detected from its extension, after the main and override configuration files like all sources.
+func WithGlob(pattern string) Option {
+	return WithSource(NewGlobSource(pattern, nil))
+}
+
+// WithFieldSetter configures how the paths of programmatic overrides are resolved to the fields of the
+// configuration. For example, fieldsetter.Setter{Tag: "yaml", CaseInsensitive: true} allows overrides to use
+// the same keys as a YAML configuration file.
+func WithFieldSetter(setter fieldsetter.Setter) Option {
+	return func(loader *ConfigLoader) {
+		loader.FieldSetter = setter
+	}
+}
+
+// WithStrict makes the ConfigLoader reject keys of the main and override configuration files that do not map
+// to any field of the configuration struct, so that typos like `databse:` fail the Load instead of being
+// silently ignored. Each unknown key is reported as an UnknownKeyError with the file, the key's path and,
+// if a known key is similar, a suggestion. Strict mode is supported by the JSON, YAML and TOML deserializers.
+// Additional FileSources can be made strict by setting their Strict field.
+func WithStrict() Option {
+	return func(loader *ConfigLoader) {
+		loader.Strict = true
+	}
+}
@@ -0,0 +1,450 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretTag is the struct tag that marks a configuration field as secret (`secret:"true"`). The values of
+// secret fields, and of all fields nested within them, are left out of their Provenance, so that they do not
+// end up in logs.
+const SecretTag = "secret"
+
+// Provenance describes where the value of a single configuration field came from. Path is the path of the
+// field as accepted by Override, Source describes the layer that set the value (the file name, "defaults"
+// or "override"), and Line and Column give the position of the value within the file where the format
+// allows it (currently YAML and JSON), or are zero otherwise. Value holds the value of the field, unless the
+// field is secret, in which case Secret is set and Value is nil. Overrode holds the provenance of the value
+// that was replaced, if any.
+type Provenance struct {
+	Path     string
+	Source   string
+	Line     int
+	Column   int
+	Value    any
+	Secret   bool
+	Overrode *Provenance
+}
+
+// String describes the Provenance in a single line, e.g.
+// `Database.Host = db.local (config.yaml:3:9, overrode localhost from defaults)`.
+func (p *Provenance) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s = %s (%s", p.Path, p.value(), p.location())
+	if p.Overrode != nil {
+		fmt.Fprintf(&b, ", overrode %s from %s", p.Overrode.value(), p.Overrode.location())
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// value formats the value of the Provenance, redacting secrets.
+func (p *Provenance) value() string {
+	if p.Secret {
+		return "***"
+	}
+	return fmt.Sprint(p.Value)
+}
+
+func (p *Provenance) location() string {
+	if p.Line == 0 {
+		return p.Source
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Source, p.Line, p.Column)
+}
+
+// Explain returns the provenance of the field at the given path from the most recent Load. Fields of embedded
+// structs can be given with or without the name of the embedded struct. The second return value reports
+// whether the path was set by any layer.
+func (c *ConfigLoader) Explain(path string) (*Provenance, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.provenance == nil {
+		return nil, false
+	}
+	if entry, ok := c.provenance.entries[path]; ok {
+		return entry, true
+	}
+	entry, ok := c.provenance.entries[c.provenance.aliases[path]]
+	return entry, ok
+}
+
+// Provenance returns the provenance of every field set by the most recent Load, ordered by path.
+func (c *ConfigLoader) Provenance() []*Provenance {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.provenance == nil {
+		return nil
+	}
+	entries := make([]*Provenance, 0, len(c.provenance.entries))
+	for _, entry := range c.provenance.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+// SkippedSources returns the optional sources that were skipped by the most recent Load because their file
+// does not exist, in the order they would have been applied.
+func (c *ConfigLoader) SkippedSources() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.provenance == nil {
+		return nil
+	}
+	return append([]string(nil), c.provenance.skipped...)
+}
+
+// ProvenanceReport returns a human readable report of the provenance of every field set by the most recent
+// Load, with one line per field, followed by a line for every optional source that was skipped.
+func (c *ConfigLoader) ProvenanceReport() string {
+	var b strings.Builder
+	for _, entry := range c.Provenance() {
+		b.WriteString(entry.String())
+		b.WriteString("\n")
+	}
+	for _, source := range c.SkippedSources() {
+		fmt.Fprintf(&b, "%s skipped (not found)\n", source)
+	}
+	return b.String()
+}
+
+// Position is the position of a key within a configuration file.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// keyPositions holds the keys a layer contains. For configuration files these are the positions of the keys by
+// lowercased, dotted key path, together with the struct tag the file's format uses to name fields. Sources
+// that set fields by path, like environment variables and overrides, record the lowercased paths they set in
+// paths instead.
+type keyPositions struct {
+	tag       string
+	positions map[string]Position
+	paths     map[string]bool
+}
+
+// appliedPaths returns the keyPositions of a layer that set the fields at the given paths.
+func appliedPaths(paths ...string) *keyPositions {
+	applied := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		applied[strings.ToLower(path)] = true
+	}
+	return &keyPositions{paths: applied}
+}
+
+// contains reports whether the layer contains the field at the given path of a configuration of type t, or
+// one of its parents. Promoted paths in paths are resolved with aliases.
+func (k *keyPositions) contains(t reflect.Type, path string, aliases map[string]string) bool {
+	if k == nil {
+		return false
+	}
+	if _, ok := k.lookup(t, path); ok {
+		return true
+	}
+	for applied := range k.paths {
+		if full, ok := aliases[applied]; ok {
+			applied = strings.ToLower(full)
+		}
+		if lower := strings.ToLower(path); lower == applied || strings.HasPrefix(lower, applied+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// locateKeys returns the positions of the keys in data for the formats that support it, or nil otherwise.
+func locateKeys(deserializer DeserializerFunc, data []byte) *keyPositions {
+	positions := make(map[string]Position)
+	switch deserializer.(type) {
+	case *YAMLDeserializer:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil
+		}
+		yamlPositions(&node, "", positions)
+		return &keyPositions{tag: "yaml", positions: positions}
+	case *JSONDeserializer:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		if err := jsonPositions(decoder, data, "", positions); err != nil {
+			return nil
+		}
+		return &keyPositions{tag: "json", positions: positions}
+	case *TOMLDeserializer:
+		// TOML keys have no position, so they are recorded at line and column zero.
+		var generic map[string]any
+		metadata, err := toml.Decode(string(data), &generic)
+		if err != nil {
+			return nil
+		}
+		for _, key := range metadata.Keys() {
+			positions[strings.ToLower(strings.Join(key, "."))] = Position{}
+		}
+		return &keyPositions{tag: "toml", positions: positions}
+	default:
+		return nil
+	}
+}
+
+func yamlPositions(node *yaml.Node, path string, positions map[string]Position) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			yamlPositions(child, path, positions)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinPath(path, strings.ToLower(key.Value))
+			positions[keyPath] = Position{Line: key.Line, Column: key.Column}
+			yamlPositions(value, keyPath, positions)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			itemPath := joinPath(path, strconv.Itoa(i))
+			positions[itemPath] = Position{Line: child.Line, Column: child.Column}
+			yamlPositions(child, itemPath, positions)
+		}
+	}
+}
+
+func jsonPositions(decoder *json.Decoder, data []byte, path string, positions map[string]Position) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			position := jsonPosition(data, decoder.InputOffset())
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			keyPath := joinPath(path, strings.ToLower(fmt.Sprint(key)))
+			positions[keyPath] = position
+			if err := jsonPositions(decoder, data, keyPath, positions); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			itemPath := joinPath(path, strconv.Itoa(i))
+			positions[itemPath] = jsonPosition(data, decoder.InputOffset())
+			if err := jsonPositions(decoder, data, itemPath, positions); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	}
+	return err
+}
+
+// jsonPosition returns the position of the next token in data after offset, skipping whitespace and the
+// separators the decoder has not consumed yet.
+func jsonPosition(data []byte, offset int64) Position {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offsetPosition(data, offset)
+}
+
+// offsetPosition returns the position of the byte at the given offset in data.
+func offsetPosition(data []byte, offset int64) Position {
+	offset = min(max(offset, 0), int64(len(data)))
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(data[:offset], '\n')
+	return Position{Line: line, Column: column}
+}
+
+// lookup returns the position of the field at the given path of a configuration of type t, translating the
+// field names into the key names of the file's format.
+func (k *keyPositions) lookup(t reflect.Type, path string) (Position, bool) {
+	var keys []string
+	for _, segment := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := t.FieldByName(segment)
+			if !ok {
+				return Position{}, false
+			}
+			name, options, _ := strings.Cut(field.Tag.Get(k.tag), ",")
+			inline := field.Anonymous && ((k.tag == "json" && name == "") || strings.Contains(options, "inline"))
+			if !inline {
+				if name == "" || name == "-" {
+					name = field.Name
+				}
+				keys = append(keys, strings.ToLower(name))
+			}
+			t = field.Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			keys = append(keys, strings.ToLower(segment))
+			t = t.Elem()
+		default:
+			return Position{}, false
+		}
+	}
+	position, ok := k.positions[strings.Join(keys, ".")]
+	return position, ok
+}
+
+// provenanceRecorder tracks which layer set each field of a configuration by comparing its leaf values
+// after every layer.
+type provenanceRecorder struct {
+	configType reflect.Type
+	leaves     map[string]any
+	aliases    map[string]string
+	entries    map[string]*Provenance
+	skipped    []string
+}
+
+func newProvenanceRecorder(config any) *provenanceRecorder {
+	leaves, aliases := flattenConfig(config)
+	return &provenanceRecorder{
+		configType: reflect.TypeOf(config),
+		leaves:     leaves,
+		aliases:    aliases,
+		entries:    make(map[string]*Provenance),
+	}
+}
+
+// record attributes every field the layer contains to the given source, even if it set the value the field
+// already had. Fields that changed are attributed to the source as well, which covers layers that cannot
+// report their keys.
+func (r *provenanceRecorder) record(config any, source string, positions *keyPositions) {
+	leaves, aliases := flattenConfig(config)
+	for path, value := range leaves {
+		previous, ok := r.leaves[path]
+		if ok && reflect.DeepEqual(previous, value) && !positions.contains(r.configType, path, aliases) {
+			continue
+		}
+		entry := &Provenance{Path: path, Source: source, Value: value, Overrode: r.entries[path]}
+		if _, ok := value.(secretValue); ok {
+			entry.Value, entry.Secret = nil, true
+		}
+		if positions != nil {
+			if position, ok := positions.lookup(r.configType, path); ok {
+				entry.Line, entry.Column = position.Line, position.Column
+			}
+		}
+		r.entries[path] = entry
+	}
+	for path := range r.entries {
+		if _, ok := leaves[path]; !ok {
+			delete(r.entries, path)
+		}
+	}
+	r.leaves, r.aliases = leaves, aliases
+}
+
+// skip records that the given optional source was skipped because its file does not exist.
+func (r *provenanceRecorder) skip(source string) {
+	r.skipped = append(r.skipped, source)
+}
+
+// secretValue wraps the leaf values of secret fields, so that they are compared like any other value but never
+// exposed by a Provenance.
+type secretValue struct {
+	value any
+}
+
+// flattenConfig returns the leaf values of config by path, together with aliases mapping the paths of fields
+// promoted from embedded structs to their full paths.
+func flattenConfig(config any) (map[string]any, map[string]string) {
+	leaves := make(map[string]any)
+	aliases := make(map[string]string)
+	flattenValue(reflect.ValueOf(config), "", "", false, leaves, aliases)
+	return leaves, aliases
+}
+
+func flattenValue(v reflect.Value, path, promoted string, secret bool, leaves map[string]any, aliases map[string]string) {
+	if !v.IsValid() {
+		return
+	}
+	if path != "" && isLeafType(v.Type()) {
+		if secret {
+			leaves[path] = secretValue{value: v.Interface()}
+		} else {
+			leaves[path] = v.Interface()
+		}
+		if promoted != path {
+			aliases[promoted] = path
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			flattenValue(v.Elem(), path, promoted, secret, leaves, aliases)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPromoted := promoted
+			if !field.Anonymous {
+				fieldPromoted = joinPath(promoted, field.Name)
+			}
+			fieldSecret := secret || field.Tag.Get(SecretTag) == "true"
+			flattenValue(v.Field(i), joinPath(path, field.Name), fieldPromoted, fieldSecret, leaves, aliases)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			index := strconv.Itoa(i)
+			flattenValue(v.Index(i), joinPath(path, index), joinPath(promoted, index), secret, leaves, aliases)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			flattenValue(iter.Value(), joinPath(path, key), joinPath(promoted, key), secret, leaves, aliases)
+		}
+	}
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isLeafType reports whether values of type t are treated as a single value rather than descended into.
+func isLeafType(t reflect.Type) bool {
+	if t.Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map:
+		return false
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() == reflect.Uint8
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
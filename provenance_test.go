@@ -0,0 +1,143 @@
+package configloader
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type ProvenanceBase struct {
+	Region string `yaml:"region" json:"region"`
+}
+
+type ProvenanceConfig struct {
+	ProvenanceBase `yaml:"base"`
+	Host           string   `yaml:"host" json:"host" default:"localhost"`
+	Port           int      `yaml:"port" json:"port" default:"80"`
+	Debug          bool     `yaml:"debug" json:"debug"`
+	Tags           []string `yaml:"tags" json:"tags"`
+}
+
+func TestProvenance(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: example.com\nbase:\n  region: eu\ntags:\n  - a\n  - b\n")
+	writeFile(t, filepath.Join(dir, "override.json"), "{\n  \"port\": 8080,\n  \"tags\": [\"c\"]\n}")
+
+	loader := NewConfigLoader("config.yaml", WithPath(dir), WithOverrideFile(dir, "override.json"),
+		WithOverrideDeserializer(new(JSONDeserializer)))
+	loader.Override("Debug", true)
+
+	var config ProvenanceConfig
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	tests := []struct {
+		path     string
+		source   string
+		line     int
+		column   int
+		overrode string
+	}{
+		{path: "Host", source: filepath.Join(dir, "config.yaml"), line: 1, column: 1, overrode: "defaults"},
+		{path: "ProvenanceBase.Region", source: filepath.Join(dir, "config.yaml"), line: 3, column: 3},
+		{path: "Region", source: filepath.Join(dir, "config.yaml"), line: 3, column: 3},
+		{path: "Port", source: filepath.Join(dir, "override.json"), line: 2, column: 3, overrode: "defaults"},
+		{path: "Tags.0", source: filepath.Join(dir, "override.json"), line: 3, column: 12, overrode: filepath.Join(dir, "config.yaml")},
+		{path: "Debug", source: "override"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			provenance, ok := loader.Explain(tt.path)
+			if !ok {
+				t.Fatalf("No provenance recorded for %s", tt.path)
+			}
+			if provenance.Source != tt.source || provenance.Line != tt.line || provenance.Column != tt.column {
+				t.Errorf("Provenance is not correct; got: %s", provenance)
+			}
+			if tt.overrode == "" && provenance.Overrode != nil {
+				t.Errorf("Expected no overridden value; got: %s", provenance)
+			}
+			if tt.overrode != "" && (provenance.Overrode == nil || provenance.Overrode.Source != tt.overrode) {
+				t.Errorf("Expected a value from %s to be overridden; got: %s", tt.overrode, provenance)
+			}
+		})
+	}
+
+	if _, ok := loader.Explain("Tags.1"); ok {
+		t.Errorf("Expected no provenance for the removed element Tags.1")
+	}
+
+	report := loader.ProvenanceReport()
+	if !strings.Contains(report, "Port = 8080 ("+filepath.Join(dir, "override.json")+":2:3, overrode 80 from defaults)") {
+		t.Errorf("Report does not describe Port correctly:\n%s", report)
+	}
+	if len(loader.Provenance()) != 5 {
+		t.Errorf("Expected provenance for 5 fields, got:\n%s", report)
+	}
+}
+
+func TestProvenanceSecrets(t *testing.T) {
+	type SecretConfig struct {
+		Password    string `yaml:"password" secret:"true" default:"changeme"`
+		Credentials struct {
+			Token string `yaml:"token"`
+		} `yaml:"credentials" secret:"true"`
+		User string `yaml:"user"`
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "password: hunter2\ncredentials:\n  token: abc123\nuser: admin\n")
+
+	loader := NewConfigLoader("config.yaml", WithPath(dir))
+	var config SecretConfig
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	provenance, _ := loader.Explain("Password")
+	if provenance == nil || !provenance.Secret || provenance.Value != nil || provenance.Overrode == nil ||
+		provenance.Overrode.Value != nil {
+		t.Errorf("Expected the secret and the value it overrode to be redacted; got: %+v", provenance)
+	}
+	report := loader.ProvenanceReport()
+	for _, secret := range []string{"hunter2", "changeme", "abc123"} {
+		if strings.Contains(report, secret) {
+			t.Errorf("Report contains the secret %q:\n%s", secret, report)
+		}
+	}
+	if !strings.Contains(report, "Password = *** (") || !strings.Contains(report, "overrode *** from defaults") ||
+		!strings.Contains(report, "User = admin (") {
+		t.Errorf("Report does not describe the fields correctly:\n%s", report)
+	}
+}
+
+func TestProvenanceUnchangedValues(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\nport: 80\n")
+	writeFile(t, filepath.Join(dir, "override.toml"), "port = 80\n")
+	t.Setenv("PROVENANCE_PORT", "80")
+
+	loader := NewConfigLoader("config.yaml", WithPath(dir), WithOverrideFile(dir, "override.toml"),
+		WithOverrideDeserializer(new(TOMLDeserializer)), WithSource(NewEnvSource("PROVENANCE")))
+	loader.Override("Host", "localhost")
+
+	var config ProvenanceConfig
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if provenance, _ := loader.Explain("Host"); provenance.Source != "override" ||
+		provenance.Overrode == nil || provenance.Overrode.Source != filepath.Join(dir, "config.yaml") {
+		t.Errorf("Expected Host to be credited to the override and the file; got: %s", provenance)
+	}
+	provenance, _ := loader.Explain("Port")
+	var sources []string
+	for p := provenance; p != nil; p = p.Overrode {
+		sources = append(sources, p.Source)
+	}
+	want := []string{`environment variables with prefix "PROVENANCE"`, filepath.Join(dir, "override.toml"),
+		filepath.Join(dir, "config.yaml"), "defaults"}
+	if strings.Join(sources, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected Port to be credited to every layer containing it; got: %v", sources)
+	}
+}
@@ -0,0 +1,116 @@
+package configloader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// StdinName is the name that selects the standard input instead of a file, as the main or override file name
+// of a ConfigLoader. As there is no extension to detect the format from, a deserializer has to be set.
+const StdinName = "-"
+
+// readStdin reads the standard input. As it can only be consumed once, it is read on the first call and
+// every later call returns the same data.
+var readStdin = sync.OnceValues(func() ([]byte, error) {
+	return io.ReadAll(os.Stdin)
+})
+
+// ReaderSource is a Source that deserializes configuration data read from Reader, e.g. a pipe or the body of
+// an HTTP request, using its Deserializer. If no Deserializer is set, the deserializer registered for the
+// extension of Name is used. Name describes where the data comes from in errors and provenance. As most
+// readers can only be consumed once, the data is read when the ReaderSource is first applied and kept for
+// later loads, so that reloads apply the same data again. Strict works as it does for a FileSource.
+type ReaderSource struct {
+	Name         string
+	Reader       io.Reader
+	Deserializer DeserializerFunc
+	Strict       bool
+
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// NewReaderSource creates a new ReaderSource for the data of the given reader, which is interpreted using the
+// given deserializer. A nil deserializer selects one based on the extension of name.
+func NewReaderSource(name string, reader io.Reader, deserializer DeserializerFunc) *ReaderSource {
+	return &ReaderSource{
+		Name:         name,
+		Reader:       reader,
+		Deserializer: deserializer,
+	}
+}
+
+// NewBytesSource creates a new ReaderSource for the given data, which is interpreted using the given
+// deserializer. A nil deserializer selects one based on the extension of name.
+func NewBytesSource(name string, data []byte, deserializer DeserializerFunc) *ReaderSource {
+	return NewReaderSource(name, bytes.NewReader(data), deserializer)
+}
+
+// NewStdinSource creates a new ReaderSource for the standard input of the process, which is interpreted using
+// the given deserializer. The standard input is read only once, even if several sources are created for it.
+func NewStdinSource(deserializer DeserializerFunc) *ReaderSource {
+	return NewReaderSource(StdinName, os.Stdin, deserializer)
+}
+
+// Apply reads the data of the ReaderSource, unless it was read before, and deserializes it onto the given
+// config object.
+func (r *ReaderSource) Apply(config any) error {
+	return r.ApplyContext(context.Background(), config)
+}
+
+// ApplyContext works like Apply, but stops waiting for the data to be read once the context is done. As
+// blocking reads cannot be interrupted, the read itself continues in the background in that case.
+func (r *ReaderSource) ApplyContext(ctx context.Context, config any) error {
+	_, err := r.applyLocated(ctx, config)
+	return err
+}
+
+func (r *ReaderSource) applyLocated(ctx context.Context, config any) (*keyPositions, error) {
+	deserializer, err := deserializerFor(r.Deserializer, r.Name, r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return applyData(ctx, r.String(), deserializer, r.Strict, data, config)
+}
+
+// read returns the data of the reader, reading it on the first call.
+func (r *ReaderSource) read(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	readAll := func() ([]byte, error) {
+		return io.ReadAll(r.Reader)
+	}
+	if r.Reader == os.Stdin {
+		readAll = readStdin
+	}
+	done := make(chan struct{})
+	go func() {
+		r.once.Do(func() {
+			r.data, r.err = readAll()
+		})
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-done:
+		return r.data, r.err
+	}
+}
+
+// String returns the name of the ReaderSource.
+func (r *ReaderSource) String() string {
+	if r.Name == StdinName {
+		return "stdin"
+	}
+	return r.Name
+}
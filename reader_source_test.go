@@ -0,0 +1,85 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ReaderConfig struct {
+	Host string `yaml:"host" json:"host"`
+	Port int    `yaml:"port" json:"port"`
+}
+
+func TestReaderSources(t *testing.T) {
+	main := NewBytesSource("defaults.yaml", []byte("host: localhost\nport: 80\n"), nil)
+	override := NewReaderSource("request body", strings.NewReader(`{"port": 8080}`), new(JSONDeserializer))
+	loader := NewConfigLoader("", WithMainSource(main), WithOverrideSource(override))
+
+	for i := 0; i < 2; i++ {
+		var config ReaderConfig
+		if err := loader.Load(&config); err != nil {
+			t.Fatalf("Failed to load configuration: %s", err)
+		}
+		if config != (ReaderConfig{Host: "localhost", Port: 8080}) {
+			t.Errorf("Expected the reader data to be applied on load %d; got: %+v", i+1, config)
+		}
+	}
+	if provenance, _ := loader.Explain("Port"); provenance.Source != "request body" || provenance.Line != 1 {
+		t.Errorf("Expected Port to come from the request body; got: %s", provenance)
+	}
+}
+
+func TestReaderSourceDecodeError(t *testing.T) {
+	var config ReaderConfig
+	err := NewBytesSource("inline", []byte("host: [\n"), new(YAMLDeserializer)).Apply(&config)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.File != "inline" {
+		t.Errorf("Expected a DecodeError for the source; got: %v", err)
+	}
+}
+
+func TestReaderSourceContext(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var config ReaderConfig
+	err := NewReaderSource("pipe", reader, new(YAMLDeserializer)).ApplyContext(ctx, &config)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the read to be aborted; got: %v", err)
+	}
+}
+
+func TestLoadFromStdin(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unable to create pipe: %s", err)
+	}
+	stdin := os.Stdin
+	os.Stdin = reader
+	defer func() { os.Stdin = stdin }()
+
+	if _, err := writer.WriteString("host: piped\n"); err != nil {
+		t.Fatalf("Unable to write to pipe: %s", err)
+	}
+	writer.Close()
+
+	var config ReaderConfig
+	loader := NewConfigLoader(StdinName, WithDeserializer(new(YAMLDeserializer)))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.Host != "piped" {
+		t.Errorf("Expected the configuration to be read from stdin; got: %+v", config)
+	}
+
+	if err := NewConfigLoader(StdinName).Load(&config); err == nil {
+		t.Error("Expected an error without a deserializer for stdin")
+	}
+}
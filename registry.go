@@ -0,0 +1,57 @@
+package configloader
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu    sync.RWMutex
+	deserializers = map[string]DeserializerFunc{
+		".json": new(JSONDeserializer),
+		".yaml": new(YAMLDeserializer),
+		".yml":  new(YAMLDeserializer),
+		".toml": new(TOMLDeserializer),
+		".env":  new(EnvDeserializer),
+	}
+)
+
+// RegisterDeserializer registers the given deserializer for files with the given extension, replacing any
+// deserializer previously registered for it. The extension is matched case-insensitively and may be given
+// with or without the leading dot. Registered deserializers are consulted by the ConfigLoader whenever no
+// deserializer is set explicitly for a configuration file.
+func RegisterDeserializer(ext string, deserializer DeserializerFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	deserializers[normalizeExt(ext)] = deserializer
+}
+
+// UnregisterDeserializer removes the deserializer registered for the given extension, including the built-in
+// ones, so that files with that extension require a deserializer to be set explicitly again.
+func UnregisterDeserializer(ext string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(deserializers, normalizeExt(ext))
+}
+
+// DeserializerForFile returns the deserializer registered for the extension of the given file name. The
+// second return value reports whether a deserializer was found.
+func DeserializerForFile(name string) (DeserializerFunc, bool) {
+	ext := filepath.Ext(name)
+	if ext == "" {
+		return nil, false
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	deserializer, ok := deserializers[normalizeExt(ext)]
+	return deserializer, ok
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
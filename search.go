@@ -0,0 +1,158 @@
+package configloader
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultSearchPaths returns the conventional locations of the configuration files of the named application,
+// in order of precedence: the working directory, the directory of the executable, the application's
+// directory in the user configuration directory ($XDG_CONFIG_HOME/<app> on Linux) and /etc/<app>. Locations
+// that cannot be determined are left out.
+func DefaultSearchPaths(app string) []string {
+	paths := []string{"."}
+	if executable, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Dir(executable))
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(configDir, app))
+	}
+	return append(paths, filepath.Join("/etc", app))
+}
+
+// SearchSource is a Source that looks for a configuration file with the given Name in each of the directories
+// in Paths, which are given in order of precedence. By default only the first match is applied. If Merge is
+// set, all matches are applied instead, starting with the last one, so that the files found earlier in Paths
+// take precedence over the ones found later. The directories are searched in FS if it is set, and in the OS
+// filesystem otherwise. If no file is found, a *SourceNotFoundError listing all
+// locations that were tried is returned, unless Optional is set. Deserializer, Strict and Optional apply to
+// every matching file as they do for a FileSource.
+type SearchSource struct {
+	Name         string
+	Paths        []string
+	Deserializer DeserializerFunc
+	FS           fs.FS
+	Merge        bool
+	Strict       bool
+	Optional     bool
+}
+
+// NewSearchSource creates a new SearchSource for the file with the given name in the given directories, which
+// is interpreted using the given deserializer. A nil deserializer selects one based on the file's extension.
+func NewSearchSource(name string, paths []string, deserializer DeserializerFunc) *SearchSource {
+	return &SearchSource{
+		Name:         name,
+		Paths:        paths,
+		Deserializer: deserializer,
+	}
+}
+
+// Candidates returns every location the SearchSource looks for its file at, in order of precedence.
+func (s *SearchSource) Candidates() []string {
+	candidates := make([]string, len(s.Paths))
+	for i, path := range s.Paths {
+		candidates[i] = s.file(path).String()
+	}
+	return candidates
+}
+
+// file returns a FileSource for the candidate in the given directory.
+func (s *SearchSource) file(path string) *FileSource {
+	file := NewFileSource(path, s.Name, s.Deserializer)
+	file.FS, file.Strict = s.FS, s.Strict
+	return file
+}
+
+// Files returns a FileSource for every existing candidate the SearchSource applies, in the order they are
+// applied.
+func (s *SearchSource) Files() []*FileSource {
+	var files []*FileSource
+	for _, path := range s.Paths {
+		file := s.file(path)
+		if info, err := s.stat(file.String()); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, file)
+		if !s.Merge {
+			break
+		}
+	}
+	slices.Reverse(files)
+	return files
+}
+
+func (s *SearchSource) stat(name string) (fs.FileInfo, error) {
+	if s.FS != nil {
+		return fs.Stat(s.FS, name)
+	}
+	return os.Stat(name)
+}
+
+// Apply applies the matching files of the SearchSource onto the given config object.
+func (s *SearchSource) Apply(config any) error {
+	return s.ApplyContext(context.Background(), config)
+}
+
+// ApplyContext works like Apply, but stops waiting for the files to be read once the context is done.
+func (s *SearchSource) ApplyContext(ctx context.Context, config any) error {
+	layers := s.layers()
+	for _, layer := range layers {
+		if _, err := applySource(ctx, layer, config); err != nil {
+			if skipped(layer, err) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// layers returns the matching files of the SearchSource as individual layers, so that the loader can record
+// the provenance of every file. If no file matches, a single layer reporting the missing file is returned.
+func (s *SearchSource) layers() []Source {
+	files := s.Files()
+	if len(files) == 0 {
+		return []Source{&missingSource{search: s}}
+	}
+	layers := make([]Source, len(files))
+	for i, file := range files {
+		layers[i] = file
+	}
+	return layers
+}
+
+// String describes the file and directories the SearchSource searches.
+func (s *SearchSource) String() string {
+	return fmt.Sprintf("%s in %s", s.Name, strings.Join(s.Paths, ", "))
+}
+
+// WatchPaths returns every candidate location of the SearchSource, so that creating a file with higher
+// precedence triggers a reload as well. Nothing is returned if the SearchSource searches an FS.
+func (s *SearchSource) WatchPaths() []string {
+	if s.FS != nil {
+		return nil
+	}
+	return s.Candidates()
+}
+
+// missingSource is the layer of a SearchSource that did not find any file.
+type missingSource struct {
+	search *SearchSource
+}
+
+func (m *missingSource) Apply(config any) error {
+	return &SourceNotFoundError{Source: m.search.Name, Tried: m.search.Candidates(), Err: os.ErrNotExist}
+}
+
+func (m *missingSource) String() string {
+	return m.search.String()
+}
+
+func (m *missingSource) optional() bool {
+	return m.search.Optional
+}
@@ -0,0 +1,19 @@
+//go:build linux
+
+package configloader
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDefaultSearchPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/user/.config")
+	paths := DefaultSearchPaths("myapp")
+	if len(paths) != 4 || paths[0] != "." || paths[3] != "/etc/myapp" {
+		t.Fatalf("Search paths are not correct; got: %v", paths)
+	}
+	if !slices.Contains(paths, "/home/user/.config/myapp") {
+		t.Errorf("Expected the XDG configuration directory to be searched; got: %v", paths)
+	}
+}
@@ -0,0 +1,66 @@
+package configloader
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type SearchConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+func TestSearchPaths(t *testing.T) {
+	local, user, system := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(user, "app.yaml"), "host: user\n")
+	writeFile(t, filepath.Join(system, "app.yaml"), "host: system\nport: 80\n")
+
+	var config SearchConfig
+	loader := NewConfigLoader("app.yaml", WithSearchPaths(local, user, system))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config != (SearchConfig{Host: "user"}) {
+		t.Errorf("Expected only the first match to be loaded; got: %+v", config)
+	}
+
+	config = SearchConfig{}
+	loader = NewConfigLoader("app.yaml", WithSearchPaths(local, user, system), WithSearchMerge())
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config != (SearchConfig{Host: "user", Port: 80}) {
+		t.Errorf("Expected all matches to be merged; got: %+v", config)
+	}
+	if provenance, _ := loader.Explain("Port"); provenance.Source != filepath.Join(system, "app.yaml") {
+		t.Errorf("Expected Port to come from the system file; got: %s", provenance)
+	}
+	if provenance, _ := loader.Explain("Host"); provenance.Source != filepath.Join(user, "app.yaml") {
+		t.Errorf("Expected Host to come from the user file; got: %s", provenance)
+	}
+}
+
+func TestSearchPathsNotFound(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+
+	var config SearchConfig
+	err := NewConfigLoader("app.yaml", WithSearchPaths(first, second)).Load(&config)
+	var notFound *SourceNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected a SourceNotFoundError; got: %v", err)
+	}
+	expected := []string{filepath.Join(first, "app.yaml"), filepath.Join(second, "app.yaml")}
+	if !slices.Equal(notFound.Tried, expected) {
+		t.Errorf("Expected all locations to be tried; got: %v", notFound.Tried)
+	}
+
+	loader := NewConfigLoader("app.yaml", WithSearchPaths(first, second), WithOptionalFile())
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Expected a missing optional file to be skipped; got: %s", err)
+	}
+	if len(loader.SkippedSources()) != 1 {
+		t.Errorf("Expected the search to be recorded as skipped; got: %v", loader.SkippedSources())
+	}
+}
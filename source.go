@@ -0,0 +1,219 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Source describes a single layer of configuration that can be applied onto a configuration object. Sources
+// are applied by the ConfigLoader in a defined order, with every source able to overwrite the values set by
+// the sources before it. This allows stacking base, per-environment, per-host and local configuration on top
+// of each other without chaining multiple loaders by hand.
+type Source interface {
+	Apply(config any) error
+	String() string
+}
+
+// ContextSource can be implemented by sources that are able to abort applying their configuration once the
+// given context is done, e.g. when reading from a slow network mount. Loaders prefer ApplyContext over Apply
+// when it is available.
+type ContextSource interface {
+	ApplyContext(ctx context.Context, config any) error
+}
+
+// locatingSource is implemented by sources that can report the positions of the keys they applied, for
+// provenance tracking.
+type locatingSource interface {
+	applyLocated(ctx context.Context, config any) (*keyPositions, error)
+}
+
+// applySource applies the given source onto config, passing on the context if the source supports it. The
+// positions of the applied keys are returned if the source is able to report them.
+func applySource(ctx context.Context, source Source, config any) (*keyPositions, error) {
+	if locating, ok := source.(locatingSource); ok {
+		return locating.applyLocated(ctx, config)
+	}
+	if contextSource, ok := source.(ContextSource); ok {
+		return nil, contextSource.ApplyContext(ctx, config)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, source.Apply(config)
+}
+
+// optionalSource is implemented by sources that can be marked as optional.
+type optionalSource interface {
+	optional() bool
+}
+
+// skipped reports whether err is caused by the file of an optional source not existing, in which case the
+// source is skipped rather than failing the Load.
+func skipped(source Source, err error) bool {
+	var notFound *SourceNotFoundError
+	optional, ok := source.(optionalSource)
+	return ok && optional.optional() && errors.As(err, &notFound)
+}
+
+// layeredSource is implemented by sources that consist of several layers, which the loader applies and
+// records the provenance of individually.
+type layeredSource interface {
+	layers() []Source
+}
+
+// expandLayers replaces every layeredSource in sources by its layers.
+func expandLayers(sources []Source) []Source {
+	var layers []Source
+	for _, source := range sources {
+		if layered, ok := source.(layeredSource); ok {
+			layers = append(layers, layered.layers()...)
+		} else {
+			layers = append(layers, source)
+		}
+	}
+	return layers
+}
+
+// FileSource is a Source that reads a single configuration file from Path and Name and deserializes it onto
+// the configuration object using its Deserializer. If no Deserializer is set, the deserializer registered for
+// the file's extension is used. The file is read from FS if it is set, e.g. to load a default configuration
+// embedded with go:embed, and from the OS filesystem otherwise. A missing file results in a
+// *SourceNotFoundError unless Optional is set, in which case the FileSource is skipped, and contents that
+// cannot be deserialized result in a *DecodeError. If Strict is set, keys of the file that do not map to any
+// field of the configuration struct are reported as UnknownKeyErrors, for deserializers implementing
+// StrictDeserializer.
+type FileSource struct {
+	Name         string
+	Path         string
+	Deserializer DeserializerFunc
+	FS           fs.FS
+	Strict       bool
+	Optional     bool
+}
+
+// NewFileSource creates a new FileSource for the file with the given path and name, which is interpreted
+// using the given deserializer. A nil deserializer selects one based on the file's extension.
+func NewFileSource(path, name string, deserializer DeserializerFunc) *FileSource {
+	return &FileSource{
+		Name:         name,
+		Path:         path,
+		Deserializer: deserializer,
+	}
+}
+
+// Apply reads the file of the FileSource and deserializes its contents onto the given config object.
+func (f *FileSource) Apply(config any) error {
+	return f.ApplyContext(context.Background(), config)
+}
+
+// ApplyContext works like Apply, but stops waiting for the file to be read once the context is done.
+func (f *FileSource) ApplyContext(ctx context.Context, config any) error {
+	_, err := f.applyLocated(ctx, config)
+	if skipped(f, err) {
+		return nil
+	}
+	return err
+}
+
+func (f *FileSource) applyLocated(ctx context.Context, config any) (*keyPositions, error) {
+	deserializer, err := deserializerFor(f.Deserializer, f.Name, f)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readFile(ctx, f.FS, f.String())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &SourceNotFoundError{Source: f.String(), Tried: []string{f.String()}, Err: err}
+	} else if err != nil {
+		return nil, err
+	}
+	return applyData(ctx, f.String(), deserializer, f.Strict, data, config)
+}
+
+// deserializerFor returns the given deserializer, or the one registered for the extension of name if it is
+// nil.
+func deserializerFor(deserializer DeserializerFunc, name string, source Source) (DeserializerFunc, error) {
+	if deserializer != nil {
+		return deserializer, nil
+	}
+	if deserializer, ok := DeserializerForFile(name); ok {
+		return deserializer, nil
+	}
+	return nil, fmt.Errorf("no deserializer set for %s", source)
+}
+
+// applyData deserializes the data read from the named origin onto config, wrapping errors as DecodeErrors,
+// and returns the positions of the applied keys.
+func applyData(ctx context.Context, name string, deserializer DeserializerFunc, strict bool, data []byte,
+	config any) (*keyPositions, error) {
+	var err error
+	if strict {
+		err = deserializeStrict(ctx, deserializer, data, config)
+	} else {
+		err = deserialize(ctx, deserializer, data, config)
+	}
+	var unknown *UnknownKeyError
+	switch {
+	case err == nil:
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		return nil, err
+	case errors.As(err, &unknown):
+		setErrorFile(err, name)
+		return nil, err
+	default:
+		return nil, newDecodeError(name, data, err)
+	}
+	return locateKeys(deserializer, data), nil
+}
+
+func (f *FileSource) optional() bool {
+	return f.Optional
+}
+
+// String returns the location of the file the FileSource reads from. Locations within an FS use forward
+// slashes on every platform, as required by fs.FS.
+func (f *FileSource) String() string {
+	if f.FS != nil {
+		return path.Join(f.Path, f.Name)
+	}
+	return filepath.Join(f.Path, f.Name)
+}
+
+// readFile reads the named file from fsys, or from the OS filesystem if fsys is nil, returning early with the
+// context's error once the context is done. As blocking reads cannot be interrupted, the read itself
+// continues in the background in that case.
+func readFile(ctx context.Context, fsys fs.FS, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	read := func() ([]byte, error) {
+		if fsys == nil {
+			return os.ReadFile(name)
+		}
+		return fs.ReadFile(fsys, name)
+	}
+	if ctx.Done() == nil {
+		return read()
+	}
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		data, err := read()
+		results <- result{data: data, err: err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-results:
+		return r.data, r.err
+	}
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package configloader
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFileSourceContextBlockedRead(t *testing.T) {
+	// Opening a FIFO without a writer blocks, like reading from an unresponsive network mount.
+	dir := t.TempDir()
+	if err := syscall.Mkfifo(filepath.Join(dir, "config.yaml"), 0o600); err != nil {
+		t.Skipf("Unable to create FIFO: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var config Config
+	err := NewFileSource(dir, "config.yaml", nil).ApplyContext(ctx, &config)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline to be exceeded, got: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package configloader
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+type FSConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+func TestLoadFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/defaults.yaml": {Data: []byte("host: localhost\nport: 80\n")},
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "local.yaml"), "port: 8080\n")
+
+	var config FSConfig
+	loader := NewConfigLoader("defaults.yaml", WithFS(fsys), WithPath("config"), WithOverrideFile(dir, "local.yaml"))
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config != (FSConfig{Host: "localhost", Port: 8080}) {
+		t.Errorf("Expected the override on disk to be layered over the FS defaults; got: %+v", config)
+	}
+	if provenance, _ := loader.Explain("Host"); provenance.Source != "config/defaults.yaml" {
+		t.Errorf("Expected Host to come from the FS; got: %s", provenance)
+	}
+	if paths := loader.watchPaths(); len(paths) != 1 || paths[0] != filepath.Join(dir, "local.yaml") {
+		t.Errorf("Expected only the file on disk to be watched; got: %v", paths)
+	}
+}
+
+func TestLoadFromFSNotFound(t *testing.T) {
+	var config FSConfig
+	err := NewConfigLoader("missing.yaml", WithFS(fstest.MapFS{})).Load(&config)
+	var notFound *SourceNotFoundError
+	if !errors.As(err, &notFound) || notFound.Source != "missing.yaml" {
+		t.Errorf("Expected a SourceNotFoundError; got: %v", err)
+	}
+}
+
+func TestSearchFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/app.yaml":   {Data: []byte("host: etc\n")},
+		"local/app.yaml": {Data: []byte("port: 1\n")},
+	}
+	var config FSConfig
+	loader := NewConfigLoader("app.yaml", WithFS(fsys), WithSearchPaths("local", "etc"), WithSearchMerge())
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config != (FSConfig{Host: "etc", Port: 1}) {
+		t.Errorf("Expected both files of the FS to be merged; got: %+v", config)
+	}
+}
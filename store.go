@@ -0,0 +1,140 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds the current configuration of type T for concurrent readers. Every reload loads into a fresh
+// value which is then swapped in atomically, so readers always see a complete configuration, and never the
+// half-written struct that reloading into a shared pointer would expose. Each swap increments the version
+// of the Store and is reported to its subscribers.
+type Store[T any] struct {
+	loader  Loader
+	current atomic.Pointer[storeEntry[T]]
+
+	// notifyMu serializes swaps together with their notifications, so that subscribers observe the versions
+	// in order. mu only guards the subscribers, so subscribers can read from and subscribe to the Store.
+	notifyMu    sync.Mutex
+	mu          sync.Mutex
+	subscribers []func(old, new T)
+}
+
+type storeEntry[T any] struct {
+	config  T
+	version uint64
+}
+
+// NewStore creates a new Store that loads its configuration with the given loader. The configuration is
+// loaded once initially, and an error is returned if that fails.
+func NewStore[T any](loader Loader) (*Store[T], error) {
+	store := &Store[T]{loader: loader}
+	config, err := NewTypedLoader[T](loader).Load()
+	if err != nil {
+		return nil, err
+	}
+	store.current.Store(&storeEntry[T]{config: config, version: 1})
+	return store, nil
+}
+
+// Get returns the current configuration. It never blocks and is safe to call from any goroutine.
+func (s *Store[T]) Get() T {
+	return s.current.Load().config
+}
+
+// Version returns the version of the current configuration, which starts at 1 and is incremented with
+// every swap.
+func (s *Store[T]) Version() uint64 {
+	return s.current.Load().version
+}
+
+// Snapshot returns the current configuration together with its version.
+func (s *Store[T]) Snapshot() (T, uint64) {
+	entry := s.current.Load()
+	return entry.config, entry.version
+}
+
+// Subscribe registers a function that is called with the previous and the new configuration after every
+// swap. Subscribers are called sequentially, in the order they were registered, and in the order of the
+// swaps (see Set).
+func (s *Store[T]) Subscribe(fn func(old, new T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Reload loads a fresh configuration with the Store's loader and swaps it in. If loading fails, the
+// current configuration is kept and the error is returned.
+func (s *Store[T]) Reload() error {
+	return s.ReloadContext(context.Background())
+}
+
+// ReloadContext works like Reload, aborting once the given context is done.
+func (s *Store[T]) ReloadContext(ctx context.Context) error {
+	config, err := NewTypedLoader[T](s.loader).LoadContext(ctx)
+	if err != nil {
+		return err
+	}
+	s.Set(config)
+	return nil
+}
+
+// Set swaps in the given configuration and notifies the subscribers. Swaps and their notifications are
+// serialized, so subscribers see every version in order, even if Set is called concurrently. Subscribers are
+// called without holding the lock that guards them, so they can call Get and Subscribe, but they must not
+// call Set or Reload of the same Store, or Reload of the Watcher returned by Watch, as those wait for the
+// notification to finish.
+func (s *Store[T]) Set(config T) {
+	s.set(config, nil)
+}
+
+// set swaps in the given configuration like Set, unless apply is set and reports false. apply is called
+// while swaps are serialized, so it can safely inspect and update state shared with other calls to set.
+func (s *Store[T]) set(config T, apply func() bool) {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	if apply != nil && !apply() {
+		return
+	}
+
+	s.mu.Lock()
+	old := s.current.Load()
+	s.current.Store(&storeEntry[T]{config: config, version: old.version + 1})
+	subscribers := append([]func(old, new T){}, s.subscribers...)
+	s.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(old.config, config)
+	}
+}
+
+// Watch starts watching the files of the Store's loader, which must be a *ConfigLoader, and swaps in every
+// configuration the Watcher reloads successfully. If the initial load of the Watcher differs from the current
+// configuration, because a file changed since the Store was created, it is swapped in as well, unless a
+// reload already swapped in a newer one. The Watcher has to be closed to stop watching.
+func (s *Store[T]) Watch(options ...WatchOption) (*Watcher, error) {
+	loader, ok := s.loader.(*ConfigLoader)
+	if !ok {
+		return nil, errors.New("watching requires the store to use a *ConfigLoader")
+	}
+	reloaded := false // guarded by notifyMu
+	subscribe := WithSubscriber(func(config any) {
+		s.set(*config.(*T), func() bool {
+			reloaded = true
+			return true
+		})
+	})
+	watcher, err := loader.Watch(func() any { return new(T) }, append([]WatchOption{subscribe}, options...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	initial := *watcher.Current().(*T)
+	s.set(initial, func() bool {
+		return !reloaded && !reflect.DeepEqual(initial, s.Get())
+	})
+	return watcher, nil
+}
@@ -0,0 +1,160 @@
+package configloader
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	loader := NewMockLoader(map[string]any{"Field1": "value1", "Field2": 1})
+	store, err := NewStore[Config](loader)
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+
+	if store.Get().Field1 != "value1" || store.Version() != 1 {
+		t.Fatalf("Initial configuration is not loaded correctly; got: %+v, version %d", store.Get(), store.Version())
+	}
+
+	var calls []string
+	store.Subscribe(func(old, new Config) {
+		calls = append(calls, old.Field1+"->"+new.Field1)
+	})
+
+	loader.Override("Field1", "value2")
+	if err := store.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %s", err)
+	}
+
+	config, version := store.Snapshot()
+	if config.Field1 != "value2" || version != 2 {
+		t.Errorf("Reloaded configuration is not correct; got: %+v, version %d", config, version)
+	}
+	if len(calls) != 1 || calls[0] != "value1->value2" {
+		t.Errorf("Subscriber was not called correctly; got: %v", calls)
+	}
+
+	loader.Override("FieldX", "invalid")
+	if err := store.Reload(); err == nil {
+		t.Fatal("Expected an error when reloading an invalid configuration, got nil")
+	}
+	if store.Get().Field1 != "value2" || store.Version() != 2 {
+		t.Errorf("Configuration should be kept on failure; got: %+v, version %d", store.Get(), store.Version())
+	}
+}
+
+func TestStoreSubscriberOrder(t *testing.T) {
+	store, err := NewStore[Config](NewMockLoader(map[string]any{"Field1": "value", "Field2": 0}))
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+
+	// Subscribers may use the Store themselves, and see the swaps in order even if Set is called concurrently.
+	var notified []int
+	store.Subscribe(func(old, new Config) {
+		if store.Get().Field2 != new.Field2 || (len(notified) > 0 && notified[len(notified)-1] != old.Field2) {
+			t.Errorf("Notification %d -> %d is out of order after %v", old.Field2, new.Field2, notified)
+		}
+		notified = append(notified, new.Field2)
+	})
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Set(Config{Field1: "value", Field2: i})
+		}()
+	}
+	wg.Wait()
+	if len(notified) != 20 || notified[19] != store.Get().Field2 || store.Version() != 21 {
+		t.Errorf("Expected the last notification to match the current configuration; got: %v, %+v", notified,
+			store.Get())
+	}
+}
+
+func TestStoreConcurrentReaders(t *testing.T) {
+	loader := NewMockLoader(map[string]any{"Field1": "value", "Field2": 0})
+	store, err := NewStore[Config](loader)
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				config, version := store.Snapshot()
+				if uint64(config.Field2)+1 != version {
+					t.Errorf("Configuration and version are inconsistent: %d, %d", config.Field2, version)
+					return
+				}
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		store.Set(Config{Field1: "value", Field2: i})
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestStoreWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: first")
+
+	store, err := NewStore[WatchConfig](NewConfigLoader("config.yaml", WithPath(dir)))
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	watcher, err := store.Watch(WithWatchMode(WatchPoll), WithPollInterval(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to watch store: %s", err)
+	}
+	defer watcher.Close()
+	if store.Version() != 1 {
+		t.Errorf("Expected an unchanged initial load not to be swapped in; got version %d", store.Version())
+	}
+
+	updated := make(chan struct{}, 10)
+	store.Subscribe(func(_, _ WatchConfig) { updated <- struct{}{} })
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: second\nport: 2")
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the store to be updated")
+	}
+	if store.Get().Name != "second" {
+		t.Errorf("Store was not updated; got: %+v", store.Get())
+	}
+}
+
+func TestStoreWatchInitialChange(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: first")
+
+	store, err := NewStore[WatchConfig](NewConfigLoader("config.yaml", WithPath(dir)))
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: second")
+	watcher, err := store.Watch(WithWatchMode(WatchPoll), WithPollInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to watch store: %s", err)
+	}
+	defer watcher.Close()
+
+	if config, version := store.Snapshot(); config.Name != "second" || version != 2 {
+		t.Errorf("Expected the changed initial load to be swapped in; got: %+v, version %d", config, version)
+	}
+}
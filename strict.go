@@ -0,0 +1,283 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// StrictDeserializer can be implemented by deserializers that are able to reject keys which do not map to any
+// field of the target struct. Loaders in strict mode use DeserializeStrict instead of Deserialize when it is
+// available, while deserializers without strict support are used as usual.
+type StrictDeserializer interface {
+	DeserializeStrict(data []byte, v any) error
+}
+
+// ContextStrictDeserializer can be implemented by deserializers that support both strict deserialization and
+// cancellation. Loaders in strict mode prefer DeserializeStrictContext over all other methods. A deserializer
+// that implements ContextDeserializer and StrictDeserializer, but not ContextStrictDeserializer, is used
+// strictly only if the context cannot be cancelled; otherwise cancellation takes precedence and
+// DeserializeContext is used.
+type ContextStrictDeserializer interface {
+	DeserializeStrictContext(ctx context.Context, data []byte, v any) error
+}
+
+// UnknownKeyError is returned in strict mode for every key of a configuration file that does not map to any
+// field of the configuration struct. Path is the dotted path of the key within the file, and Line and Column
+// give its position if the format allows it. Suggestion holds the most similar known key, if any is close
+// enough to likely be meant.
+type UnknownKeyError struct {
+	File       string
+	Path       string
+	Line       int
+	Column     int
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File)
+		if e.Line != 0 {
+			fmt.Fprintf(&b, ":%d:%d", e.Line, e.Column)
+		}
+		b.WriteString(": ")
+	} else if e.Line != 0 {
+		fmt.Fprintf(&b, "line %d: ", e.Line)
+	}
+	fmt.Fprintf(&b, "unknown key %q", e.Path)
+	if e.Suggestion != "" {
+		fmt.Fprintf(&b, " (did you mean %q?)", e.Suggestion)
+	}
+	return b.String()
+}
+
+// setErrorFile sets the file of all UnknownKeyErrors contained in err.
+func setErrorFile(err error, file string) {
+	var unknown *UnknownKeyError
+	if errors.As(err, &unknown) && unknown.File == "" {
+		unknown.File = file
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			setErrorFile(err, file)
+		}
+	}
+}
+
+// keyFormat describes how a configuration format names the fields of a struct.
+type keyFormat struct {
+	tag             string
+	caseInsensitive bool
+	defaultName     func(field reflect.StructField) string
+	inlineEmbedded  bool
+}
+
+var (
+	yamlKeys = keyFormat{tag: "yaml", defaultName: func(field reflect.StructField) string {
+		return strings.ToLower(field.Name)
+	}}
+	jsonKeys = keyFormat{tag: "json", caseInsensitive: true, inlineEmbedded: true, defaultName: fieldName}
+	tomlKeys = keyFormat{tag: "toml", caseInsensitive: true, inlineEmbedded: true, defaultName: fieldName}
+)
+
+func fieldName(field reflect.StructField) string {
+	return field.Name
+}
+
+// fields returns the types of the fields of the struct type t by the key the format uses for them.
+func (f keyFormat) fields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get(f.tag), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		inline := strings.Contains(options, "inline") || (f.inlineEmbedded && field.Anonymous && name == "")
+		if inline && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range f.fields(fieldType) {
+				if _, ok := fields[embeddedName]; !ok {
+					fields[embeddedName] = embeddedType
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.defaultName(field)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// field returns the type of the field of the struct type t the format maps the given key to.
+func (f keyFormat) field(t reflect.Type, key string) (reflect.Type, bool) {
+	fields := f.fields(t)
+	if fieldType, ok := fields[key]; ok {
+		return fieldType, true
+	}
+	if f.caseInsensitive {
+		for name, fieldType := range fields {
+			if strings.EqualFold(name, key) {
+				return fieldType, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// unknownKeys returns the paths of all keys in the generically decoded data that do not map to a field of
+// the type t.
+func (f keyFormat) unknownKeys(data any, t reflect.Type, path []string) [][]string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	var unknown [][]string
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := data.(map[string]any)
+		if !ok {
+			return nil
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := append(append([]string{}, path...), key)
+			fieldType, ok := f.field(t, key)
+			if !ok {
+				unknown = append(unknown, keyPath)
+				continue
+			}
+			unknown = append(unknown, f.unknownKeys(m[key], fieldType, keyPath)...)
+		}
+	case reflect.Map:
+		if m, ok := data.(map[string]any); ok {
+			for key, value := range m {
+				unknown = append(unknown, f.unknownKeys(value, t.Elem(), append(append([]string{}, path...), key))...)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := data.([]any); ok {
+			for i, item := range items {
+				unknown = append(unknown, f.unknownKeys(item, t.Elem(), append(append([]string{}, path...), strconv.Itoa(i)))...)
+			}
+		}
+	}
+	return unknown
+}
+
+// suggest returns the known key most similar to the last segment of path within the struct addressed by the
+// preceding segments, or an empty string if none is similar enough.
+func (f keyFormat) suggest(t reflect.Type, path []string) string {
+	for _, segment := range path[:len(path)-1] {
+		t = elemUnlessIndexed(t, segment)
+		switch t.Kind() {
+		case reflect.Struct:
+			var ok bool
+			if t, ok = f.field(t, segment); !ok {
+				return ""
+			}
+		case reflect.Map, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return ""
+		}
+	}
+	key := path[len(path)-1]
+	if t = elemUnlessIndexed(t, key); t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	best, bestDistance := "", utf8.RuneCountInString(key)/2+1
+	for name := range f.fields(t) {
+		distance := levenshtein(strings.ToLower(key), strings.ToLower(name))
+		if distance < bestDistance || (distance == bestDistance && best != "" && name < best) {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// elemUnlessIndexed dereferences the type t and, if it is a slice or array while segment is not an index,
+// steps through to its element type. Formats like TOML report the keys of arrays of tables without the index
+// of the table they appear in.
+func elemUnlessIndexed(t reflect.Type, segment string) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if _, err := strconv.Atoi(segment); err != nil {
+			t = t.Elem()
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+		}
+	}
+	return t
+}
+
+// unknownKeyErrors returns an error for every unknown key in the generically decoded data, or nil if all
+// keys are known.
+func (f keyFormat) unknownKeyErrors(data any, v any, positions *keyPositions) error {
+	t := reflect.TypeOf(v)
+	var errs []error
+	for _, path := range f.unknownKeys(data, t, nil) {
+		errs = append(errs, f.unknownKeyError(t, path, positions))
+	}
+	return errors.Join(errs...)
+}
+
+func (f keyFormat) unknownKeyError(t reflect.Type, path []string, positions *keyPositions) *UnknownKeyError {
+	err := &UnknownKeyError{Path: strings.Join(path, "."), Suggestion: f.suggest(t, path)}
+	if positions != nil {
+		if position, ok := positions.positions[strings.ToLower(err.Path)]; ok {
+			err.Line, err.Column = position.Line, position.Column
+		}
+	}
+	return err
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
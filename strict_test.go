@@ -0,0 +1,161 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+type StrictDatabase struct {
+	Host string `yaml:"host" json:"host" toml:"host"`
+	Port int    `yaml:"port" json:"port" toml:"port"`
+}
+
+type StrictConfig struct {
+	Name     string         `yaml:"name" json:"name" toml:"name"`
+	Database StrictDatabase `yaml:"database" json:"database" toml:"database"`
+	Replicas []StrictDatabase
+}
+
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []UnknownKeyError
+	}{
+		{
+			name:    "YAML",
+			file:    "config.yaml",
+			content: "name: app\ndatabse:\n  host: db\ndatabase:\n  hots: db\n",
+			expected: []UnknownKeyError{
+				{Path: "database.hots", Line: 5, Column: 3, Suggestion: "host"},
+				{Path: "databse", Line: 2, Column: 1, Suggestion: "database"},
+			},
+		},
+		{
+			name:    "JSON",
+			file:    "config.json",
+			content: "{\n  \"name\": \"app\",\n  \"Replicas\": [{\"prot\": 1}]\n}",
+			expected: []UnknownKeyError{
+				{Path: "Replicas.0.prot", Line: 3, Column: 17, Suggestion: "port"},
+			},
+		},
+		{
+			name:    "TOML",
+			file:    "config.toml",
+			content: "nmae = \"app\"\n\n[database]\nport = 5432\n\n[unrelated]\nkey = 1\n\n[[Replicas]]\nhots = \"db\"\n",
+			expected: []UnknownKeyError{
+				{Path: "nmae", Suggestion: "name"},
+				{Path: "unrelated"},
+				{Path: "Replicas.hots", Suggestion: "host"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, tt.file), tt.content)
+
+			var config StrictConfig
+			if err := NewConfigLoader(tt.file, WithPath(dir)).Load(&config); err != nil {
+				t.Fatalf("Unknown keys should be ignored without strict mode; got: %s", err)
+			}
+
+			err := NewConfigLoader(tt.file, WithPath(dir), WithStrict()).Load(&config)
+			if err == nil {
+				t.Fatal("Expected an error for unknown keys")
+			}
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok || len(joined.Unwrap()) != len(tt.expected) {
+				t.Fatalf("Expected %d unknown keys; got: %s", len(tt.expected), err)
+			}
+			for i, err := range joined.Unwrap() {
+				var unknown *UnknownKeyError
+				if !errors.As(err, &unknown) {
+					t.Fatalf("Expected an UnknownKeyError; got: %s", err)
+				}
+				expected := tt.expected[i]
+				expected.File = filepath.Join(dir, tt.file)
+				if *unknown != expected {
+					t.Errorf("Unknown key error is not correct; got: %+v, want: %+v", *unknown, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestStrictKnownKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: app\ndatabase:\n  host: db\n  port: 5432\n")
+	writeFile(t, filepath.Join(dir, "empty.yaml"), "")
+
+	var config StrictConfig
+	loader := NewConfigLoader("config.yaml", WithPath(dir), WithOverrideFile(dir, "empty.yaml"), WithStrict())
+	if err := loader.Load(&config); err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+	if config.Name != "app" || config.Database.Host != "db" || config.Database.Port != 5432 {
+		t.Errorf("Configuration is not correct; got: %+v", config)
+	}
+}
+
+func TestUnknownKeyErrorString(t *testing.T) {
+	err := &UnknownKeyError{File: "config.yaml", Path: "databse", Line: 2, Column: 1, Suggestion: "database"}
+	expected := `config.yaml:2:1: unknown key "databse" (did you mean "database"?)`
+	if err.Error() != expected {
+		t.Errorf("Error message is not correct; got: %s, want: %s", err, expected)
+	}
+}
+
+// recordingDeserializer records which of its methods the loader called.
+type recordingDeserializer struct {
+	called string
+}
+
+func (r *recordingDeserializer) Deserialize(data []byte, v any) error {
+	r.called = "Deserialize"
+	return nil
+}
+
+func (r *recordingDeserializer) DeserializeStrict(data []byte, v any) error {
+	r.called = "DeserializeStrict"
+	return nil
+}
+
+func (r *recordingDeserializer) DeserializeContext(ctx context.Context, data []byte, v any) error {
+	r.called = "DeserializeContext"
+	return ctx.Err()
+}
+
+type recordingStrictContextDeserializer struct {
+	recordingDeserializer
+}
+
+func (r *recordingStrictContextDeserializer) DeserializeStrictContext(ctx context.Context, data []byte, v any) error {
+	r.called = "DeserializeStrictContext"
+	return ctx.Err()
+}
+
+func TestStrictContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var config StrictConfig
+
+	deserializer := new(recordingDeserializer)
+	if err := deserializeStrict(ctx, deserializer, nil, &config); err != nil || deserializer.called != "DeserializeContext" {
+		t.Errorf("Expected a cancellable context to reach the deserializer; got: %v from %s", err, deserializer.called)
+	}
+	if err := deserializeStrict(context.Background(), deserializer, nil, &config); err != nil ||
+		deserializer.called != "DeserializeStrict" {
+		t.Errorf("Expected strict deserialization without cancellation; got: %v from %s", err, deserializer.called)
+	}
+
+	strictContext := new(recordingStrictContextDeserializer)
+	cancel()
+	if err := deserializeStrict(ctx, strictContext, nil, &config); !errors.Is(err, context.Canceled) ||
+		strictContext.called != "DeserializeStrictContext" {
+		t.Errorf("Expected strict deserialization with the context; got: %v from %s", err, strictContext.called)
+	}
+}
@@ -0,0 +1,48 @@
+package configloader
+
+import "context"
+
+// TypedLoader wraps a Loader, like a ConfigLoader or a MockLoader, to load configurations of the concrete type T.
+// Instead of passing a pointer to Load and relying on runtime checks, the loaded configuration is returned
+// as a value of type T, so that passing a wrong type is caught by the compiler.
+type TypedLoader[T any] struct {
+	Loader Loader
+}
+
+// NewTypedLoader creates a new TypedLoader for configurations of type T that uses the given loader.
+func NewTypedLoader[T any](loader Loader) *TypedLoader[T] {
+	return &TypedLoader[T]{Loader: loader}
+}
+
+// Load loads a new configuration of type T using the wrapped Loader. If loading fails, the zero value of T is
+// returned together with the error.
+func (t *TypedLoader[T]) Load() (T, error) {
+	return t.LoadContext(context.Background())
+}
+
+// LoadContext works like Load, aborting once the given context is done.
+func (t *TypedLoader[T]) LoadContext(ctx context.Context) (T, error) {
+	var config T
+	if err := t.Loader.LoadContext(ctx, &config); err != nil {
+		var zero T
+		return zero, err
+	}
+	return config, nil
+}
+
+// Override adds or updates a configuration override on the wrapped Loader.
+func (t *TypedLoader[T]) Override(path string, value any) error {
+	return t.Loader.Override(path, value)
+}
+
+// Load creates a ConfigLoader for the configuration file with the given name and options, and loads a
+// configuration of type T with it. It is a shorthand for the common case of loading a configuration once
+// at startup.
+func Load[T any](name string, options ...Option) (T, error) {
+	return NewTypedLoader[T](NewConfigLoader(name, options...)).Load()
+}
+
+// LoadContext works like Load, aborting once the given context is done.
+func LoadContext[T any](ctx context.Context, name string, options ...Option) (T, error) {
+	return NewTypedLoader[T](NewConfigLoader(name, options...)).LoadContext(ctx)
+}
@@ -0,0 +1,61 @@
+package configloader_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/snippetaccumulator/configloader"
+)
+
+func TestLoadTyped(t *testing.T) {
+	filename, err := createTempYAMLFile([]byte("field1: value1\nfield2: 2\nnested:\n  field3: true"))
+	if err != nil {
+		t.Fatalf("Unable to create temp YAML file: %s", err)
+	}
+	defer os.Remove(filename)
+
+	config, err := configloader.Load[Config](filepath.Base(filename), configloader.WithPath(filepath.Dir(filename)))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if config.Field1 != "value1" {
+		t.Errorf("Expected field1 to be 'value1', got '%s'", config.Field1)
+	}
+	if config.Nested.Field3 != true {
+		t.Errorf("Expected nested.field3 to be true, got %t", config.Nested.Field3)
+	}
+}
+
+func TestLoadTypedError(t *testing.T) {
+	config, err := configloader.Load[Config]("does_not_exist.yaml")
+	if err == nil {
+		t.Fatal("Expected an error for non-existent file, got nil")
+	}
+	if config != (Config{}) {
+		t.Errorf("Expected the zero value on error, got %+v", config)
+	}
+}
+
+func TestTypedLoaderWithMockLoader(t *testing.T) {
+	loader := configloader.NewTypedLoader[Config](configloader.NewMockLoader(map[string]any{
+		"Field1": "value1",
+		"Field2": 2,
+	}))
+	if err := loader.Override("Field2", 3); err != nil {
+		t.Fatalf("Failed to set override: %s", err)
+	}
+
+	config, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if config.Field1 != "value1" {
+		t.Errorf("Expected field1 to be 'value1', got '%s'", config.Field1)
+	}
+	if config.Field2 != 3 {
+		t.Errorf("Expected field2 to be 3, got %d", config.Field2)
+	}
+}
@@ -0,0 +1,307 @@
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/snippetaccumulator/configloader/fieldsetter"
+)
+
+// ValidateTag is the struct tag holding the validation rules of a configuration field.
+const ValidateTag = "validate"
+
+// Validator can be implemented by configuration types, and types nested within them, to perform validation
+// that cannot be expressed with struct tags. Validate is called after all rules of the struct's fields have
+// been checked.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError is returned when a configuration fails validation. It lists every failing field rather
+// than only the first one.
+type ValidationError struct {
+	Errors []*RuleError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, "; "))
+}
+
+// Unwrap returns the individual errors of the ValidationError, for use with errors.Is and errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// RuleError describes a single validation rule that failed for the field at Path. Rule is the name of the
+// rule, or "Validate" for errors returned by a Validator.
+type RuleError struct {
+	Path string
+	Rule string
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "config"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks the given config object against the rules given in its `validate` struct tags, and calls
+// the Validate method of the config object and any nested struct implementing Validator. Rules are separated
+// by commas:
+//
+//   - required: the field must not hold its zero value,
+//   - min=N, max=N: numbers must be within the bounds, which are parsed into the type of the field (so
+//     durations can be given as "1s"); strings, slices and maps must have a length within the bounds,
+//   - oneof=a b c: the field must be one of the space separated values,
+//   - regex=EXPR: strings must match the regular expression; as it may contain commas, it must be the last rule,
+//   - url: strings must be absolute URLs,
+//   - hostport: strings must be of the form host:port,
+//   - file_exists: strings must name an existing file.
+//
+// Apart from required, min and max, rules are skipped for fields holding their zero value, and no further
+// rules are checked for a field once required failed. Nested structs,
+// pointers, slices and maps are descended into. All failing rules are returned together in a ValidationError.
+func Validate(config any) error {
+	v := reflect.ValueOf(config)
+	if !v.IsValid() {
+		return errors.New("config must not be nil")
+	}
+	errs := validateValue(v, "")
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validateValue(v reflect.Value, path string) []*RuleError {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var errs []*RuleError
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := joinPath(path, field.Name)
+			if field.Anonymous {
+				fieldPath = path
+			}
+			if rules, ok := field.Tag.Lookup(ValidateTag); ok {
+				errs = append(errs, validateRules(v.Field(i), fieldPath, rules)...)
+			}
+			errs = append(errs, validateValue(v.Field(i), fieldPath)...)
+		}
+		if err := callValidator(v); err != nil {
+			errs = append(errs, &RuleError{Path: path, Rule: "Validate", Err: err})
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateValue(v.Index(i), joinPath(path, strconv.Itoa(i)))...)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			errs = append(errs, validateValue(iter.Value(), joinPath(path, fmt.Sprint(iter.Key().Interface())))...)
+		}
+	}
+	return errs
+}
+
+// callValidator calls the Validate method of the struct v, using its address if v is addressable so that
+// methods with pointer receivers are found as well.
+func callValidator(v reflect.Value) error {
+	if v.CanAddr() {
+		if validator, ok := v.Addr().Interface().(Validator); ok {
+			return validator.Validate()
+		}
+	}
+	if validator, ok := v.Interface().(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+func validateRules(v reflect.Value, path string, rules string) []*RuleError {
+	var errs []*RuleError
+	for rules = strings.TrimSpace(rules); rules != ""; rules = strings.TrimSpace(rules) {
+		var rule string
+		if strings.HasPrefix(rules, "regex=") {
+			rule, rules = rules, ""
+		} else {
+			rule, rules, _ = strings.Cut(rules, ",")
+		}
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "" {
+			continue
+		}
+		if err := validateRule(v, name, arg); err != nil {
+			errs = append(errs, &RuleError{Path: path, Rule: name, Err: err})
+			if name == "required" {
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// stringRules are the validation rules that only apply to strings.
+var stringRules = map[string]bool{"regex": true, "url": true, "hostport": true, "file_exists": true}
+
+func validateRule(v reflect.Value, name, arg string) error {
+	if name != "required" && name != "min" && name != "max" && name != "oneof" && !stringRules[name] {
+		return fmt.Errorf("unknown validation rule %s", name)
+	}
+
+	switch name {
+	case "required":
+		if v.IsZero() {
+			return errors.New("is required")
+		}
+		return nil
+	}
+
+	// A nil pointer is an unset optional value, to which only required applies. A set pointer is validated by
+	// the value it points to, even if that is the zero value.
+	set := !v.IsZero()
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if name == "min" || name == "max" {
+		return validateBound(v, name, arg)
+	}
+	// Misspelled rules and rules for the wrong type are reported even for zero values, which skip all other
+	// checks.
+	if stringRules[name] && v.Kind() != reflect.String {
+		return fmt.Errorf("rule %s is not supported for type %s", name, v.Type())
+	}
+	if !set {
+		return nil
+	}
+	if name == "oneof" {
+		value := fmt.Sprint(v.Interface())
+		options := strings.Fields(arg)
+		for _, option := range options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s, got %q", strings.Join(options, ", "), value)
+	}
+
+	s := v.String()
+	switch name {
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression %q: %w", arg, err)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("must match %s, got %q", arg, s)
+		}
+	case "url":
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+			return fmt.Errorf("must be an absolute URL, got %q", s)
+		}
+	case "hostport":
+		_, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return fmt.Errorf("must be of the form host:port, got %q", s)
+		}
+		if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+			return fmt.Errorf("must have a port between 1 and 65535, got %q", port)
+		}
+	case "file_exists":
+		info, err := os.Stat(s)
+		if err != nil {
+			return fmt.Errorf("must be an existing file: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("must be a file, %s is a directory", s)
+		}
+	default:
+		return fmt.Errorf("unknown validation rule %s", name)
+	}
+	return nil
+}
+
+func validateBound(v reflect.Value, name, arg string) error {
+	var actual, bound float64
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid %s length %q", name, arg)
+		}
+		actual, bound = float64(v.Len()), float64(n)
+		if name == "min" && actual < bound {
+			return fmt.Errorf("must have a length of at least %d, got %d", n, v.Len())
+		}
+		if name == "max" && actual > bound {
+			return fmt.Errorf("must have a length of at most %d, got %d", n, v.Len())
+		}
+		return nil
+	}
+
+	boundValue, err := fieldsetter.Convert(arg, v.Type())
+	if err != nil {
+		return fmt.Errorf("invalid %s bound %q: %w", name, arg, err)
+	}
+	switch {
+	case v.CanInt():
+		actual, bound = float64(v.Int()), float64(boundValue.Int())
+	case v.CanUint():
+		actual, bound = float64(v.Uint()), float64(boundValue.Uint())
+	case v.CanFloat():
+		actual, bound = v.Float(), boundValue.Float()
+	default:
+		return fmt.Errorf("rule %s is not supported for type %s", name, v.Type())
+	}
+	if name == "min" && actual < bound {
+		return fmt.Errorf("must be at least %v, got %v", boundValue.Interface(), v.Interface())
+	}
+	if name == "max" && actual > bound {
+		return fmt.Errorf("must be at most %v, got %v", boundValue.Interface(), v.Interface())
+	}
+	return nil
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
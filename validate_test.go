@@ -0,0 +1,162 @@
+package configloader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type ValidatedServer struct {
+	Address string `validate:"required,hostport"`
+}
+
+type ValidatedConfig struct {
+	Name     string            `validate:"required,min=3,max=10"`
+	Port     int               `validate:"min=1,max=65535"`
+	Timeout  time.Duration     `validate:"min=1s"`
+	Level    string            `validate:"oneof=debug info warn"`
+	Version  string            `validate:"regex=^v[0-9]+(\\.[0-9]+){0,2}$"`
+	Homepage string            `validate:"url"`
+	CertFile string            `validate:"file_exists"`
+	Servers  []ValidatedServer `validate:"min=1"`
+	Optional string            `validate:"url"`
+	Replicas int
+}
+
+func (c *ValidatedConfig) Validate() error {
+	if c.Replicas > len(c.Servers) {
+		return errors.New("more replicas than servers")
+	}
+	return nil
+}
+
+func validConfig(t *testing.T) ValidatedConfig {
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, []byte("cert"), 0o600); err != nil {
+		t.Fatalf("Unable to write certificate file: %s", err)
+	}
+	return ValidatedConfig{
+		Name:     "service",
+		Port:     8080,
+		Timeout:  5 * time.Second,
+		Level:    "info",
+		Version:  "v1.2",
+		Homepage: "https://example.com",
+		CertFile: certFile,
+		Servers:  []ValidatedServer{{Address: "localhost:80"}},
+		Replicas: 1,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	config := validConfig(t)
+	if err := Validate(&config); err != nil {
+		t.Fatalf("Expected valid configuration, got: %s", err)
+	}
+}
+
+func TestValidateAggregatesErrors(t *testing.T) {
+	config := validConfig(t)
+	config.Name = ""
+	config.Port = 0
+	config.Timeout = time.Millisecond
+	config.Level = "trace"
+	config.Version = "1.2"
+	config.Homepage = "example.com"
+	config.CertFile = filepath.Join(t.TempDir(), "missing.pem")
+	config.Servers = []ValidatedServer{{Address: "localhost"}, {}}
+	config.Replicas = 3
+
+	err := Validate(&config)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got: %v", err)
+	}
+
+	want := map[string]string{
+		"Name":              "required",
+		"Port":              "min",
+		"Timeout":           "min",
+		"Level":             "oneof",
+		"Version":           "regex",
+		"Homepage":          "url",
+		"CertFile":          "file_exists",
+		"Servers.0.Address": "hostport",
+		"Servers.1.Address": "required",
+		"":                  "Validate",
+	}
+	got := make(map[string]string)
+	for _, ruleErr := range validationErr.Errors {
+		got[ruleErr.Path] = ruleErr.Rule
+	}
+	for path, rule := range want {
+		if got[path] != rule {
+			t.Errorf("Expected rule %s to fail for %q, got %q", rule, path, got[path])
+		}
+	}
+	if len(validationErr.Errors) != len(want) {
+		t.Errorf("Expected %d errors, got %d: %s", len(want), len(validationErr.Errors), err)
+	}
+}
+
+func TestValidateLengths(t *testing.T) {
+	config := validConfig(t)
+	config.Name = "a-very-long-name"
+	config.Servers = nil
+
+	err := Validate(&config)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 3 {
+		t.Fatalf("Expected errors for Name, Servers and Validate, got: %v", err)
+	}
+}
+
+func TestValidateRuleSyntax(t *testing.T) {
+	var config struct {
+		Code string `validate:"required, regex=^a,b$"`
+	}
+	config.Code = "a,b"
+	if err := Validate(&config); err != nil {
+		t.Errorf("Expected the regex after a space to include the comma, got: %s", err)
+	}
+	config.Code = "a"
+	if err := Validate(&config); err == nil {
+		t.Error("Expected an error for a value not matching the regex")
+	}
+}
+
+func TestValidatePointers(t *testing.T) {
+	type PointerConfig struct {
+		Port  *int    `validate:"min=1,max=65535"`
+		Level *string `validate:"oneof=debug info"`
+	}
+	if err := Validate(&PointerConfig{}); err != nil {
+		t.Errorf("Expected nil pointers to be skipped, got: %s", err)
+	}
+
+	port, level := 8080, "info"
+	if err := Validate(&PointerConfig{Port: &port, Level: &level}); err != nil {
+		t.Errorf("Expected valid pointers, got: %s", err)
+	}
+
+	port, level = 0, "trace"
+	err := Validate(&PointerConfig{Port: &port, Level: &level})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 2 {
+		t.Errorf("Expected errors for Port and Level, got: %v", err)
+	}
+}
+
+func TestValidateUnknownRuleOnZeroValue(t *testing.T) {
+	type V struct {
+		Name string `validate:"requried"`
+		Port int    `validate:"url"`
+	}
+	err := Validate(&V{})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 2 {
+		t.Errorf("Expected errors for the unknown rule and the unsupported type, got: %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package configloader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WatchableSource can be implemented by sources that read from the filesystem, to have a Watcher reload the
+// configuration when the returned files or directories change.
+type WatchableSource interface {
+	WatchPaths() []string
+}
+
+// WatchPaths returns the file the FileSource reads from, or nothing if it reads from an FS.
+func (f *FileSource) WatchPaths() []string {
+	if f.FS != nil {
+		return nil
+	}
+	return []string{f.String()}
+}
+
+// WatchMode selects how a Watcher detects changes to the configuration files.
+type WatchMode int
+
+const (
+	// WatchAuto uses filesystem notifications where the platform supports them, and polling otherwise.
+	WatchAuto WatchMode = iota
+	// WatchPoll periodically compares the modification time, size and identity of the files.
+	WatchPoll
+	// WatchNotify uses filesystem notifications (inotify on Linux) and fails on other platforms.
+	WatchNotify
+)
+
+// WatchOption defines a function signature for optional configuration functions that customize a Watcher.
+type WatchOption func(watcher *Watcher)
+
+// WithWatchMode sets how the Watcher detects changes. The default is WatchAuto.
+func WithWatchMode(mode WatchMode) WatchOption {
+	return func(watcher *Watcher) {
+		watcher.mode = mode
+	}
+}
+
+// WithPollInterval sets the interval in which a polling Watcher checks the files for changes. It also is the
+// time a notification based Watcher waits for further events before reloading, so that a burst of writes
+// results in a single reload. The default is one second.
+func WithPollInterval(interval time.Duration) WatchOption {
+	return func(watcher *Watcher) {
+		watcher.interval = interval
+	}
+}
+
+// WithWatchErrorHandler sets a function that is called with the error whenever a reload fails. The Watcher
+// keeps the last good configuration in that case.
+func WithWatchErrorHandler(handler func(err error)) WatchOption {
+	return func(watcher *Watcher) {
+		watcher.onError = handler
+	}
+}
+
+// WithSubscriber registers a function that is called with every new configuration after a successful reload,
+// like Subscribe. Unlike a later call to Subscribe, it is registered before the Watcher starts watching, so
+// that no reload can be missed.
+func WithSubscriber(fn func(config any)) WatchOption {
+	return func(watcher *Watcher) {
+		watcher.subscribers = append(watcher.subscribers, fn)
+	}
+}
+
+// Watcher reloads the configuration of a ConfigLoader whenever one of its files changes, and delivers every
+// configuration that loads and validates successfully to its subscribers. Each reload runs the full Load
+// pipeline into a fresh configuration object obtained from the factory passed to Watch, so subscribers never
+// observe a partially loaded configuration. If a reload fails, the last good configuration is kept.
+type Watcher struct {
+	loader    *ConfigLoader
+	newConfig func() any
+	mode      WatchMode
+	interval  time.Duration
+	onError   func(err error)
+
+	mu          sync.Mutex
+	current     any
+	subscribers []func(config any)
+
+	reloadMu  sync.Mutex
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+	closer    func() error
+}
+
+// Watch loads the configuration into a new object returned by newConfig, which must return a pointer, and
+// starts watching the main and override files as well as all sources implementing WatchableSource for
+// changes. Watching starts before the initial load, so that files changing while it runs trigger a reload.
+// An error is returned if the initial load fails or the files cannot be watched.
+func (c *ConfigLoader) Watch(newConfig func() any, options ...WatchOption) (*Watcher, error) {
+	watcher := &Watcher{
+		loader:    c,
+		newConfig: newConfig,
+		interval:  time.Second,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, option := range options {
+		option(watcher)
+	}
+
+	// Reloads triggered by changes during the initial load wait for it to complete, and then load the changed
+	// files.
+	watcher.reloadMu.Lock()
+	paths := c.watchPaths()
+	notifying := false
+	if watcher.mode != WatchPoll {
+		err := watcher.startNotify(paths)
+		if err != nil && watcher.mode == WatchNotify {
+			watcher.reloadMu.Unlock()
+			return nil, err
+		}
+		notifying = err == nil
+	}
+	if !notifying {
+		go watcher.poll(paths, snapshotPaths(paths))
+	}
+
+	config := newConfig()
+	err := c.Load(config)
+	if err == nil {
+		watcher.current = config
+	}
+	watcher.reloadMu.Unlock()
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// Current returns the last configuration that was loaded successfully.
+func (w *Watcher) Current() any {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Subscribe registers a function that is called with every new configuration after a successful reload.
+// Subscribers are called sequentially, from the goroutine performing the reload.
+func (w *Watcher) Subscribe(fn func(config any)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload runs the Load pipeline into a fresh configuration object and, if it succeeds, makes it the current
+// configuration and delivers it to the subscribers. It is called automatically on file changes, but can also
+// be called manually, e.g. on SIGHUP. On failure the current configuration is kept and the error is returned.
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	config := w.newConfig()
+	if err := w.loader.Load(config); err != nil {
+		if w.onError != nil {
+			w.onError(err)
+		}
+		return err
+	}
+
+	w.mu.Lock()
+	w.current = config
+	subscribers := append([]func(config any){}, w.subscribers...)
+	w.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(config)
+	}
+	return nil
+}
+
+// Close stops watching for changes. It is safe to call Close multiple times.
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		if w.closer != nil {
+			err = w.closer()
+		}
+		<-w.done
+	})
+	return err
+}
+
+// watchPaths returns the paths of all watchable layers of the ConfigLoader.
+func (c *ConfigLoader) watchPaths() []string {
+	var paths []string
+	for _, source := range c.layers() {
+		if watchable, ok := source.(WatchableSource); ok {
+			paths = append(paths, watchable.WatchPaths()...)
+		}
+	}
+	return paths
+}
+
+// poll checks the given paths for changes against the last snapshot in the configured interval until the
+// Watcher is closed.
+func (w *Watcher) poll(paths []string, last pathSnapshot) {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			current := snapshotPaths(paths)
+			if !current.equal(last) {
+				last = current
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+// debounce calls Reload once no further event was received on events for the configured interval. It
+// returns once the Watcher is closed or events is closed.
+func (w *Watcher) debounce(events <-chan struct{}) {
+	defer close(w.done)
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-w.stop:
+			return
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			timer = time.After(w.interval)
+		case <-timer:
+			timer = nil
+			_ = w.Reload()
+		}
+	}
+}
+
+// pathSnapshot records the state of watched files, so that modifications as well as replacements (like the
+// symlink swaps of Kubernetes volumes) can be detected.
+type pathSnapshot map[string]os.FileInfo
+
+// snapshotPaths records the state of all given paths. Directories are recorded together with the files
+// they contain.
+func snapshotPaths(paths []string) pathSnapshot {
+	snapshot := make(pathSnapshot)
+	for _, path := range paths {
+		snapshot.add(path)
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			snapshot.add(filepath.Join(path, entry.Name()))
+		}
+	}
+	return snapshot
+}
+
+func (s pathSnapshot) add(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	s[path] = info
+}
+
+func (s pathSnapshot) equal(other pathSnapshot) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for path, info := range s {
+		otherInfo, ok := other[path]
+		if !ok || !info.ModTime().Equal(otherInfo.ModTime()) || info.Size() != otherInfo.Size() ||
+			!os.SameFile(info, otherInfo) {
+			return false
+		}
+	}
+	return true
+}
+
+// watchTargets groups the given paths by the directory that has to be watched for them. Directories are
+// watched themselves, with every change inside them being relevant, while files are watched through their
+// parent directory, so that files replaced by editors or atomic renames are noticed. A nil name set means
+// every change in the directory is relevant.
+func watchTargets(paths []string) (map[string]map[string]bool, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no files to watch")
+	}
+	targets := make(map[string]map[string]bool)
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			targets[path] = nil
+			continue
+		}
+		dir, name := filepath.Split(path)
+		dir = filepath.Clean(dir)
+		names, ok := targets[dir]
+		if ok && names == nil {
+			continue
+		}
+		if names == nil {
+			names = make(map[string]bool)
+			targets[dir] = names
+		}
+		names[name] = true
+	}
+	return targets, nil
+}
+
+// sortedKeys returns the directories of the given watch targets in lexical order.
+func sortedKeys(targets map[string]map[string]bool) []string {
+	dirs := make([]string, 0, len(targets))
+	for dir := range targets {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
@@ -0,0 +1,85 @@
+//go:build linux
+
+package configloader
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_ATTRIB |
+	syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_DELETE
+
+// startNotify watches the directories of the given paths with inotify and reloads the configuration on
+// relevant events.
+func (w *Watcher) startNotify(paths []string) error {
+	targets, err := watchTargets(paths)
+	if err != nil {
+		return err
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return os.NewSyscallError("inotify_init1", err)
+	}
+	// As the descriptor is non-blocking, the file uses the runtime poller and Close unblocks pending reads.
+	file := os.NewFile(uintptr(fd), "inotify")
+
+	dirs := make(map[int32]string)
+	for _, dir := range sortedKeys(targets) {
+		wd, err := syscall.InotifyAddWatch(fd, dir, inotifyMask)
+		if err == syscall.ENOENT {
+			// Directories of search paths or optional files may not exist.
+			continue
+		} else if err != nil {
+			file.Close()
+			return &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+		}
+		dirs[int32(wd)] = dir
+	}
+	if len(dirs) == 0 {
+		file.Close()
+		return errors.New("none of the directories to watch exist")
+	}
+
+	events := make(chan struct{}, 1)
+	w.closer = file.Close
+	go readNotify(file, targets, dirs, events)
+	go w.debounce(events)
+	return nil
+}
+
+// readNotify reads inotify events from file and signals events for every event affecting a watched path,
+// until file is closed. Events for entries starting with ".." are always relevant, as Kubernetes updates
+// mounted volumes by swapping the "..data" symlink the visible files point through.
+func readNotify(file *os.File, targets map[string]map[string]bool, dirs map[int32]string, events chan<- struct{}) {
+	defer close(events)
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := file.Read(buf)
+		if err != nil {
+			return
+		}
+		relevant := false
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			name := strings.TrimRight(string(nameBytes), "\x00")
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			names := targets[dirs[event.Wd]]
+			if names == nil || names[name] || strings.HasPrefix(name, "..") {
+				relevant = true
+			}
+		}
+		if relevant {
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchNotify(t *testing.T) {
+	testWatch(t, WatchNotify)
+}
+
+func TestWatchNotifyChangeDuringLoad(t *testing.T) {
+	testWatchChangeDuringLoad(t, WatchNotify)
+}
+
+func TestWatchNotifyRename(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: first")
+
+	watcher, err := NewConfigLoader("config.yaml", WithPath(dir)).Watch(func() any { return new(WatchConfig) },
+		WithWatchMode(WatchNotify),
+		WithPollInterval(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to watch configuration: %s", err)
+	}
+	defer watcher.Close()
+
+	updates := make(chan *WatchConfig, 10)
+	watcher.Subscribe(func(config any) { updates <- config.(*WatchConfig) })
+
+	// Editors and deployment tools commonly replace files by renaming a temporary file over them.
+	writeFile(t, filepath.Join(dir, "config.yaml.tmp"), "name: renamed")
+	if err := os.Rename(filepath.Join(dir, "config.yaml.tmp"), filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatalf("Unable to rename configuration: %s", err)
+	}
+	select {
+	case config := <-updates:
+		if config.Name != "renamed" {
+			t.Fatalf("Reloaded configuration is not correct; got: %+v", config)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the configuration to be reloaded")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package configloader
+
+import "errors"
+
+// startNotify is not supported on this platform, so Watchers in WatchAuto mode fall back to polling.
+func (w *Watcher) startNotify(_ []string) error {
+	return errors.New("filesystem notifications are not supported on this platform")
+}
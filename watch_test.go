@@ -0,0 +1,127 @@
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type WatchConfig struct {
+	Name string `yaml:"name" validate:"required"`
+	Port int    `yaml:"port"`
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Unable to write %s: %s", path, err)
+	}
+}
+
+func testWatch(t *testing.T, mode WatchMode) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: first\nport: 1")
+
+	loader := NewConfigLoader("config.yaml", WithPath(dir))
+	errs := make(chan error, 10)
+	watcher, err := loader.Watch(func() any { return new(WatchConfig) },
+		WithWatchMode(mode),
+		WithPollInterval(20*time.Millisecond),
+		WithWatchErrorHandler(func(err error) { errs <- err }),
+	)
+	if err != nil {
+		t.Fatalf("Failed to watch configuration: %s", err)
+	}
+	defer watcher.Close()
+
+	if config := watcher.Current().(*WatchConfig); config.Name != "first" {
+		t.Fatalf("Initial configuration is not loaded correctly; got: %+v", config)
+	}
+
+	updates := make(chan *WatchConfig, 10)
+	watcher.Subscribe(func(config any) { updates <- config.(*WatchConfig) })
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: second\nport: 22")
+	select {
+	case config := <-updates:
+		if config.Name != "second" || config.Port != 22 {
+			t.Fatalf("Reloaded configuration is not correct; got: %+v", config)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the configuration to be reloaded")
+	}
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "port: 333")
+	select {
+	case <-errs:
+	case config := <-updates:
+		t.Fatalf("Invalid configuration should not be delivered; got: %+v", config)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the reload error")
+	}
+	if config := watcher.Current().(*WatchConfig); config.Name != "second" {
+		t.Fatalf("Last good configuration should be kept; got: %+v", config)
+	}
+}
+
+func TestWatchPoll(t *testing.T) {
+	testWatch(t, WatchPoll)
+}
+
+func TestWatchAuto(t *testing.T) {
+	testWatch(t, WatchAuto)
+}
+
+// rewritingSource rewrites a file the first time it is applied, imitating a change during the initial load.
+type rewritingSource struct {
+	once    sync.Once
+	path    string
+	content string
+}
+
+func (r *rewritingSource) Apply(config any) error {
+	r.once.Do(func() { os.WriteFile(r.path, []byte(r.content), 0o600) })
+	return nil
+}
+
+func (r *rewritingSource) String() string {
+	return "rewrite " + r.path
+}
+
+func testWatchChangeDuringLoad(t *testing.T, mode WatchMode) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, "name: first")
+
+	loader := NewConfigLoader("config.yaml", WithPath(dir),
+		WithSource(&rewritingSource{path: path, content: "name: second"}))
+	watcher, err := loader.Watch(func() any { return new(WatchConfig) },
+		WithWatchMode(mode),
+		WithPollInterval(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to watch configuration: %s", err)
+	}
+	defer watcher.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for watcher.Current().(*WatchConfig).Name != "second" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Change during the initial load was missed; got: %+v", watcher.Current())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchPollChangeDuringLoad(t *testing.T) {
+	testWatchChangeDuringLoad(t, WatchPoll)
+}
+
+func TestWatchInitialLoadFails(t *testing.T) {
+	loader := NewConfigLoader("missing.yaml", WithPath(t.TempDir()))
+	if _, err := loader.Watch(func() any { return new(WatchConfig) }); err == nil {
+		t.Fatal("Expected an error when the initial load fails, got nil")
+	}
+}